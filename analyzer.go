@@ -0,0 +1,530 @@
+package lodestar
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+const (
+	// bracketChars are the brackets removed by the bracket filters by default.
+	bracketChars = "()[]{}"
+
+	// underscorePlaceholder stands in for underscores to find the tokens made by replacing underscores.
+	underscorePlaceholder = "\x1a"
+)
+
+// TokenInfo describes a token produced from a value by a tokenizer, see Index.Analyze.
+type TokenInfo struct {
+	Text string
+
+	// Position is the index of the word of the value that the token starts at, or 0 if it is unknown.
+	Position int
+
+	// Variation is the rule that produced the token.
+	Variation TokenVariation
+}
+
+// CharFilter transforms a value before it is split into words. Char filters are applied to queries as well.
+type CharFilter func(value string) string
+
+// Splitter splits a value into words.
+type Splitter func(value string) []string
+
+// TokenFilter transforms the tokens produced from a value, e.g. by removing them or adding variations.
+type TokenFilter interface {
+	// FilterTokens returns the filtered tokens. The value is passed as it was before the char filters.
+	FilterTokens(value string, tokens []TokenInfo) []TokenInfo
+}
+
+// QueryFilter can be implemented by a TokenFilter to transform normalized strings as well, so that
+// queries match the filtered tokens, e.g. by stemming their words.
+type QueryFilter interface {
+	FilterQuery(normalized string) string
+}
+
+// TokenFilterFunc is a TokenFilter that only filters the tokens of values.
+type TokenFilterFunc func(value string, tokens []TokenInfo) []TokenInfo
+
+func (f TokenFilterFunc) FilterTokens(value string, tokens []TokenInfo) []TokenInfo {
+	return f(value, tokens)
+}
+
+// Analyzer implements the Tokenizer interface as a pipeline of char filters, a splitter and token filters.
+// Values are transformed by the char filters and split into words, which are the initial tokens, with the
+// TokenVariationWord variation. The token filters then transform the tokens in order, e.g. SuffixFilter
+// replaces the words with the word suffixes of the value.
+//
+// Strings are normalized by the char filters, followed by the token filters implementing QueryFilter.
+// Queries are searched in the variants of HyphenFilter and BracketFilter as well, see TokenizeQuery.
+type Analyzer struct {
+	charFilters  []CharFilter
+	splitter     Splitter
+	tokenFilters []TokenFilter
+}
+
+// NewAnalyzer creates an Analyzer without filters, splitting values on whitespace.
+// Filters are added with the With methods, e.g.:
+//
+//	analyzer := lodestar.NewAnalyzer().
+//		WithCharFilters(lodestar.LowercaseCharFilter(), lodestar.TrimSpaceCharFilter()).
+//		WithTokenFilters(lodestar.SuffixFilter(), lodestar.StemmingFilter(nil))
+func NewAnalyzer() *Analyzer {
+	return &Analyzer{splitter: WhitespaceSplitter()}
+}
+
+// NewDefaultAnalyzer creates the Analyzer that the DefaultTokenizer with the stopwords is expressed as:
+//
+//	NewAnalyzer().
+//		WithCharFilters(LowercaseCharFilter(), TrimSpaceCharFilter(), UnderscoreCharFilter(4)).
+//		WithTokenFilters(SuffixFilter(), HyphenFilter(4), StopwordsFilter(stopwords), BracketFilter(4))
+//
+// See DefaultTokenizerConfig for the presets of other configurations.
+func NewDefaultAnalyzer(stopwords Stopwords) *Analyzer {
+	return (&DefaultTokenizer{Stopwords: stopwords}).buildAnalyzer()
+}
+
+// WithCharFilters appends char filters to the analyzer and returns it.
+func (a *Analyzer) WithCharFilters(filters ...CharFilter) *Analyzer {
+	a.charFilters = append(a.charFilters, filters...)
+	return a
+}
+
+// WithSplitter sets the splitter of the analyzer and returns it.
+func (a *Analyzer) WithSplitter(splitter Splitter) *Analyzer {
+	a.splitter = splitter
+	return a
+}
+
+// WithTokenFilters appends token filters to the analyzer and returns it.
+func (a *Analyzer) WithTokenFilters(filters ...TokenFilter) *Analyzer {
+	a.tokenFilters = append(a.tokenFilters, filters...)
+	return a
+}
+
+func (a *Analyzer) Tokenize(item IndexableItem) []string {
+	var allTokens []string
+	for _, value := range item.GetValuesForIndexing() {
+		tokens := a.analyze(value)
+		if len(tokens) == 0 {
+			slog.Warn("Tokenization for item value returned an empty set", "value", value, "item", item)
+			continue
+		}
+		allTokens = mergeUniqueTokens(allTokens, tokenTexts(tokens))
+	}
+	return allTokens
+}
+
+// NormalizeString applies the char filters and the query filters to the value.
+func (a *Analyzer) NormalizeString(value string) string {
+	normalized := a.filterChars(value)
+	for _, filter := range a.tokenFilters {
+		if queryFilter, ok := filter.(QueryFilter); ok {
+			normalized = queryFilter.FilterQuery(normalized)
+		}
+	}
+	return normalized
+}
+
+// queryVariantFilter is implemented by token filters that search a variant of queries as well.
+type queryVariantFilter interface {
+	queryVariant(normalized string) string
+}
+
+// TokenizeQuery returns the normalized query, followed by the variants of the query and of the previous
+// variants from the token filters, e.g. the query with hyphens replaced by spaces from HyphenFilter.
+func (a *Analyzer) TokenizeQuery(query string) []string {
+	queries := []string{a.NormalizeString(query)}
+	for _, filter := range a.tokenFilters {
+		variantFilter, ok := filter.(queryVariantFilter)
+		if !ok {
+			continue
+		}
+		for _, q := range queries {
+			if variant := variantFilter.queryVariant(q); !slices.Contains(queries, variant) {
+				queries = append(queries, variant)
+			}
+		}
+	}
+	return queries
+}
+
+// Analyze returns the tokens of the value in the order they were produced by the pipeline, each with
+// the rule that produced it and the position of the word it starts at. A token produced by several
+// rules is listed once, with the first rule. Word suffixes that only exist because underscores were
+// replaced with spaces have the TokenVariationUnderscore variation.
+func (a *Analyzer) Analyze(value string) []TokenInfo {
+	tokens := uniqueTokenInfos(a.analyze(value))
+	if !strings.Contains(value, "_") {
+		return tokens
+	}
+
+	// Find the tokens the pipeline produces when underscores are kept
+	literal := make(map[string]struct{})
+	for _, token := range a.analyze(strings.ReplaceAll(value, "_", underscorePlaceholder)) {
+		literal[strings.ReplaceAll(token.Text, underscorePlaceholder, "_")] = struct{}{}
+	}
+	for i, token := range tokens {
+		if _, found := literal[token.Text]; !found && token.Variation == TokenVariationWordSuffix {
+			tokens[i].Variation = TokenVariationUnderscore
+		}
+	}
+	return tokens
+}
+
+// tokenVariation returns the variation of the token of the value with the text.
+func (a *Analyzer) tokenVariation(value string, token string) TokenVariation {
+	return tokenInfoVariation(a.Analyze(value), token)
+}
+
+// analyze runs the pipeline on a single value. The tokens may contain duplicate texts.
+func (a *Analyzer) analyze(value string) []TokenInfo {
+	words := a.splitter(a.filterChars(value))
+	tokens := make([]TokenInfo, 0, len(words))
+	for i, word := range words {
+		tokens = append(tokens, TokenInfo{Text: word, Position: i, Variation: TokenVariationWord})
+	}
+	for _, filter := range a.tokenFilters {
+		tokens = filter.FilterTokens(value, tokens)
+	}
+	return tokens
+}
+
+func (a *Analyzer) filterChars(value string) string {
+	for _, filter := range a.charFilters {
+		value = filter(value)
+	}
+	return value
+}
+
+// LowercaseCharFilter lowercases values.
+func LowercaseCharFilter() CharFilter {
+	return strings.ToLower
+}
+
+// TrimSpaceCharFilter removes leading and trailing whitespace.
+func TrimSpaceCharFilter() CharFilter {
+	return strings.TrimSpace
+}
+
+// UnderscoreCharFilter replaces underscores with spaces in values of at least minLength bytes.
+func UnderscoreCharFilter(minLength int) CharFilter {
+	return func(value string) string {
+		if len(value) < minLength {
+			return value
+		}
+		return strings.ReplaceAll(value, "_", " ")
+	}
+}
+
+// BracketCharFilter removes all brackets from values, so that only the stripped form is indexed.
+// See BracketFilter to index both forms.
+func BracketCharFilter() CharFilter {
+	return func(value string) string {
+		return stripChars(value, bracketChars)
+	}
+}
+
+// SeparatorCharFilter replaces any of the separators with spaces, so that they split words like whitespace,
+// e.g. "/|" to split "a/b" into "a" and "b".
+func SeparatorCharFilter(separators string) CharFilter {
+	return func(value string) string {
+		return strings.Map(func(r rune) rune {
+			if strings.ContainsRune(separators, r) {
+				return ' '
+			}
+			return r
+		}, value)
+	}
+}
+
+// WhitespaceSplitter splits values on whitespace.
+func WhitespaceSplitter() Splitter {
+	return splitOnWhitespace
+}
+
+// IdentifierSplitter splits values into lowercase words like the CodeTokenizer, on case changes and on
+// all characters other than letters and digits.
+func IdentifierSplitter() Splitter {
+	return splitIdentifier
+}
+
+// LowercaseFilter lowercases tokens and queries.
+func LowercaseFilter() TokenFilter {
+	return lowercaseFilter{}
+}
+
+type lowercaseFilter struct{}
+
+func (lowercaseFilter) FilterTokens(_ string, tokens []TokenInfo) []TokenInfo {
+	for i := range tokens {
+		tokens[i].Text = strings.ToLower(tokens[i].Text)
+	}
+	return tokens
+}
+
+func (lowercaseFilter) FilterQuery(normalized string) string {
+	return strings.ToLower(normalized)
+}
+
+// SuffixFilter replaces the words with the word suffixes of the value, i.e. the words from each word
+// to the end joined by spaces, so that a query matches from any word boundary.
+// The tokens have the TokenVariationWordSuffix variation.
+func SuffixFilter() TokenFilter {
+	return TokenFilterFunc(func(_ string, tokens []TokenInfo) []TokenInfo {
+		words := make([]string, len(tokens))
+		for i, token := range tokens {
+			words[i] = token.Text
+		}
+		suffixes := make([]TokenInfo, len(tokens))
+		for i, suffix := range computePrefixCombinations(words) {
+			suffixes[i] = TokenInfo{Text: suffix, Position: tokens[i].Position, Variation: TokenVariationWordSuffix}
+		}
+		return suffixes
+	})
+}
+
+// HyphenFilter adds the word suffixes of the value with hyphens replaced by spaces, if the value has
+// hyphens and at least minLength bytes, so that a query matches from the words within hyphenated words.
+// It follows SuffixFilter, and the tokens have the TokenVariationDashless variation.
+// Queries with hyphens are also searched with hyphens replaced by spaces, e.g. "just-works" finds "just works".
+func HyphenFilter(minLength int) TokenFilter {
+	return hyphenFilter{minLength: minLength}
+}
+
+type hyphenFilter struct {
+	minLength int
+}
+
+func (f hyphenFilter) FilterTokens(value string, tokens []TokenInfo) []TokenInfo {
+	if !strings.Contains(value, "-") || len(value) < f.minLength {
+		return tokens
+	}
+	filtered := tokens
+	for _, token := range tokens {
+		if token.Variation != TokenVariationWordSuffix {
+			continue
+		}
+		// Add the dashless suffixes starting within the first word of the suffix
+		firstWord, _, _ := strings.Cut(token.Text, " ")
+		starts := len(strings.Fields(strings.ReplaceAll(firstWord, "-", " ")))
+		words := strings.Fields(strings.ReplaceAll(token.Text, "-", " "))
+		for i := range starts {
+			filtered = append(filtered, TokenInfo{
+				Text:      strings.Join(words[i:], " "),
+				Position:  token.Position,
+				Variation: TokenVariationDashless,
+			})
+		}
+	}
+	return filtered
+}
+
+func (f hyphenFilter) queryVariant(normalized string) string {
+	if len(normalized) < f.minLength {
+		return normalized
+	}
+	return strings.ReplaceAll(normalized, "-", " ")
+}
+
+// StopwordsFilter removes the tokens starting with a stopword, unless all tokens of the same variation
+// start with a stopword, so that stopwords are kept inside tokens but do not start them.
+// Leading stopwords are removed from queries as long as another word follows.
+func StopwordsFilter(stopwords Stopwords) TokenFilter {
+	return stopwordsFilter{stopwords: stopwords}
+}
+
+type stopwordsFilter struct {
+	stopwords Stopwords
+}
+
+func (f stopwordsFilter) FilterTokens(_ string, tokens []TokenInfo) []TokenInfo {
+	if len(f.stopwords) == 0 {
+		return tokens
+	}
+	kept := make(map[TokenVariation]bool)
+	for _, token := range tokens {
+		if !f.startsWithStopword(token) {
+			kept[token.Variation] = true
+		}
+	}
+	filtered := tokens[:0:0]
+	for _, token := range tokens {
+		if !kept[token.Variation] || !f.startsWithStopword(token) {
+			filtered = append(filtered, token)
+		}
+	}
+	return filtered
+}
+
+func (f stopwordsFilter) startsWithStopword(token TokenInfo) bool {
+	firstWord, _, _ := strings.Cut(token.Text, " ")
+	return f.stopwords.Contains(firstWord)
+}
+
+func (f stopwordsFilter) FilterQuery(normalized string) string {
+	return f.stopwords.trimLeading(normalized)
+}
+
+// BracketFilter adds the tokens of at least minLength bytes with their brackets removed, so that values
+// with brackets are found with and without them, e.g. "(hello world)" by "hello world".
+// The tokens have the TokenVariationBracketStripped variation.
+// Queries with brackets are also searched with their brackets removed, e.g. "hello (world)" finds "hello world".
+func BracketFilter(minLength int) TokenFilter {
+	return StripFilter(minLength, bracketChars)
+}
+
+// StripFilter is a BracketFilter removing any of the chars instead of brackets, e.g. `()[]{}"'` to
+// strip quotes as well.
+func StripFilter(minLength int, chars string) TokenFilter {
+	return stripFilter{minLength: minLength, chars: chars}
+}
+
+type stripFilter struct {
+	minLength int
+	chars     string
+}
+
+func (f stripFilter) FilterTokens(_ string, tokens []TokenInfo) []TokenInfo {
+	filtered := tokens
+	for _, token := range tokens {
+		if len(token.Text) < f.minLength || !strings.ContainsAny(token.Text, f.chars) {
+			continue
+		}
+		if stripped := stripChars(token.Text, f.chars); stripped != token.Text {
+			filtered = append(filtered, TokenInfo{
+				Text:      stripped,
+				Position:  token.Position,
+				Variation: TokenVariationBracketStripped,
+			})
+		}
+	}
+	return filtered
+}
+
+func (f stripFilter) queryVariant(normalized string) string {
+	if len(normalized) < f.minLength {
+		return normalized
+	}
+	return stripChars(normalized, f.chars)
+}
+
+// MaxWordsFilter truncates tokens to their first maxWords words, bounding the size of the word suffixes
+// of long values. Queries with more words than maxWords no longer match.
+func MaxWordsFilter(maxWords int) TokenFilter {
+	return TokenFilterFunc(func(_ string, tokens []TokenInfo) []TokenInfo {
+		for i, token := range tokens {
+			if words := strings.Fields(token.Text); len(words) > maxWords && !isNamespacedToken(token.Text) {
+				tokens[i].Text = strings.Join(words[:maxWords], " ")
+			}
+		}
+		return tokens
+	})
+}
+
+// StemmingFilter adds the tokens with their words stemmed, and stems the words of queries.
+// If stemmer is nil, the EnglishStemmer is used. The tokens have the TokenVariationStemmed variation.
+func StemmingFilter(stemmer Stemmer) TokenFilter {
+	if stemmer == nil {
+		stemmer = EnglishStemmer{}
+	}
+	return stemmingFilter{stemmer: stemmer}
+}
+
+type stemmingFilter struct {
+	stemmer Stemmer
+}
+
+func (f stemmingFilter) FilterTokens(_ string, tokens []TokenInfo) []TokenInfo {
+	filtered := tokens
+	for _, token := range tokens {
+		if stemmed := stemWords(f.stemmer, token.Text); stemmed != token.Text {
+			filtered = append(filtered, TokenInfo{Text: stemmed, Position: token.Position, Variation: TokenVariationStemmed})
+		}
+	}
+	return filtered
+}
+
+func (f stemmingFilter) FilterQuery(normalized string) string {
+	return stemWords(f.stemmer, normalized)
+}
+
+// SynonymsFilter adds the tokens with their terms replaced by synonyms, so that the synonyms are
+// expanded when indexing rather than when searching. The tokens have the TokenVariationSynonym variation.
+func SynonymsFilter(synonyms *Synonyms) TokenFilter {
+	return TokenFilterFunc(func(_ string, tokens []TokenInfo) []TokenInfo {
+		if synonyms == nil {
+			return tokens
+		}
+		filtered := tokens
+		for _, token := range tokens {
+			for _, alternative := range synonyms.expand(token.Text) {
+				filtered = append(filtered, TokenInfo{Text: alternative, Position: token.Position, Variation: TokenVariationSynonym})
+			}
+		}
+		return filtered
+	})
+}
+
+// stripChars removes any of the chars from the value.
+func stripChars(value string, chars string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(chars, r) {
+			return -1
+		}
+		return r
+	}, value)
+}
+
+// tokenAnalyzer is implemented by tokenizers that describe the tokens they produce from a value.
+type tokenAnalyzer interface {
+	Analyze(value string) []TokenInfo
+}
+
+// analyzeValue returns the tokens the tokenizer produces from the value. The tokens of tokenizers that
+// do not implement Analyze have their variation from tokenVariation, if implemented, and a position of 0.
+func analyzeValue(tokenizer Tokenizer, value string) []TokenInfo {
+	if analyzer, ok := tokenizer.(tokenAnalyzer); ok {
+		return analyzer.Analyze(value)
+	}
+	explainer, _ := tokenizer.(tokenVariationExplainer)
+	tokens := tokenizer.Tokenize(&valueItem{value: value})
+	infos := make([]TokenInfo, len(tokens))
+	for i, token := range tokens {
+		infos[i] = TokenInfo{Text: token, Variation: TokenVariationUnknown}
+		if explainer != nil {
+			infos[i].Variation = explainer.tokenVariation(value, token)
+		}
+	}
+	return infos
+}
+
+// uniqueTokenInfos removes the tokens whose text was already listed, in place.
+func uniqueTokenInfos(tokens []TokenInfo) []TokenInfo {
+	seen := make(map[string]struct{}, len(tokens))
+	return slices.DeleteFunc(tokens, func(token TokenInfo) bool {
+		if _, found := seen[token.Text]; found {
+			return true
+		}
+		seen[token.Text] = struct{}{}
+		return false
+	})
+}
+
+// tokenInfoVariation returns the variation of the token with the text.
+func tokenInfoVariation(tokens []TokenInfo, text string) TokenVariation {
+	for _, token := range tokens {
+		if token.Text == text {
+			return token.Variation
+		}
+	}
+	return TokenVariationUnknown
+}
+
+func tokenTexts(tokens []TokenInfo) []string {
+	texts := make([]string, len(tokens))
+	for i, token := range tokens {
+		texts[i] = token.Text
+	}
+	return texts
+}
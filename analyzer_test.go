@@ -0,0 +1,190 @@
+package lodestar
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNewDefaultAnalyzer(t *testing.T) {
+	tests := []struct {
+		stopwords  Stopwords
+		value      string
+		want       []string
+		normalized string
+	}{
+		{nil, "Hello World", []string{"hello world", "world"}, "hello world"},
+		{nil, "  it-just-works  ", []string{"it just works", "it-just-works", "just works", "works"}, "it-just-works"},
+		{nil, "snake_case_value", []string{"case value", "snake case value", "value"}, "snake case value"},
+		{nil, "a_b", []string{"a_b"}, "a_b"},
+		{nil, "(hello world) [beta]", []string{"(hello world) [beta]", "[beta]", "beta", "hello world beta", "world beta", "world) [beta]"}, "(hello world) [beta]"},
+		{nil, "the lord of the rings", []string{"lord of the rings", "of the rings", "rings", "the lord of the rings", "the rings"}, "the lord of the rings"},
+		{nil, "of the", []string{"of the", "the"}, "of the"},
+		{nil, "x-ray", []string{"ray", "x ray", "x-ray"}, "x-ray"},
+		{BuiltinStopwords("en"), "Hello World", []string{"hello world", "world"}, "hello world"},
+		{BuiltinStopwords("en"), "  it-just-works  ", []string{"it-just-works", "just works", "works"}, "it-just-works"},
+		{BuiltinStopwords("en"), "snake_case_value", []string{"case value", "snake case value", "value"}, "snake case value"},
+		{BuiltinStopwords("en"), "a_b", []string{"a_b"}, "a_b"},
+		{BuiltinStopwords("en"), "(hello world) [beta]", []string{"(hello world) [beta]", "[beta]", "beta", "hello world beta", "world beta", "world) [beta]"}, "(hello world) [beta]"},
+		{BuiltinStopwords("en"), "the lord of the rings", []string{"lord of the rings", "rings"}, "lord of the rings"},
+		{BuiltinStopwords("en"), "of the", []string{"of the", "the"}, "the"},
+		{BuiltinStopwords("en"), "x-ray", []string{"ray", "x ray", "x-ray"}, "x-ray"},
+	}
+	for _, tt := range tests {
+		analyzer := NewDefaultAnalyzer(tt.stopwords)
+		if got := analyzer.Tokenize(&valueItem{value: tt.value}); !slices.Equal(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+		if got := analyzer.NormalizeString(tt.value); got != tt.normalized {
+			t.Errorf("NormalizeString(%q) = %q, want %q", tt.value, got, tt.normalized)
+		}
+	}
+}
+
+func TestAnalyzer_analyze(t *testing.T) {
+	analyzer := NewDefaultAnalyzer(nil)
+	got := analyzer.analyze("Big-Bang (Theory)")
+	want := []TokenInfo{
+		{Text: "big-bang (theory)", Position: 0, Variation: TokenVariationWordSuffix},
+		{Text: "(theory)", Position: 1, Variation: TokenVariationWordSuffix},
+		{Text: "big bang (theory)", Position: 0, Variation: TokenVariationDashless},
+		{Text: "bang (theory)", Position: 0, Variation: TokenVariationDashless},
+		{Text: "(theory)", Position: 1, Variation: TokenVariationDashless},
+		{Text: "big-bang theory", Position: 0, Variation: TokenVariationBracketStripped},
+		{Text: "theory", Position: 1, Variation: TokenVariationBracketStripped},
+		{Text: "big bang theory", Position: 0, Variation: TokenVariationBracketStripped},
+		{Text: "bang theory", Position: 0, Variation: TokenVariationBracketStripped},
+		{Text: "theory", Position: 1, Variation: TokenVariationBracketStripped},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("analyze() = %v, want %v", got, want)
+	}
+}
+
+func TestAnalyzer_Custom(t *testing.T) {
+	synonyms := NewSynonyms()
+	synonyms.Add("config", "settings")
+	analyzer := NewAnalyzer().
+		WithSplitter(IdentifierSplitter()).
+		WithTokenFilters(SuffixFilter(), StemmingFilter(nil), SynonymsFilter(synonyms))
+
+	got := analyzer.Tokenize(&valueItem{value: "LoadingServerConfig"})
+	want := []string{
+		"config", "load server config", "load server settings", "loading server config", "loading server settings",
+		"server config", "server settings", "settings",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Tokenize() = %q, want %q", got, want)
+	}
+	if got := analyzer.NormalizeString("loading servers"); got != "load server" {
+		t.Errorf("NormalizeString() = %q, want %q", got, "load server")
+	}
+	if got := analyzer.tokenVariation("LoadingServerConfig", "server settings"); got != TokenVariationSynonym {
+		t.Errorf("tokenVariation() = %v, want %v", got, TokenVariationSynonym)
+	}
+
+	items := []*ExampleItem{
+		{Text: "LoadingServerConfig", Rank: 2},
+		{Text: "ServerMetrics", Rank: 1},
+	}
+	index, _ := New[*ExampleItem](WithTokenizer(analyzer)).IndexItems(items)
+	for query, want := range map[string][]string{
+		"server":       {"LoadingServerConfig", "ServerMetrics"},
+		"load server":  {"LoadingServerConfig"},
+		"settin":       {"LoadingServerConfig"},
+		"servers metr": {"ServerMetrics"},
+	} {
+		results, _ := index.PrefixSearch(query, 0, nil)
+		got := resultTexts(results)
+		if !slices.Equal(got, want) {
+			t.Errorf("PrefixSearch(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
+
+// upperTokenizer is a Tokenizer that does not describe its tokens.
+type upperTokenizer struct{}
+
+func (upperTokenizer) Tokenize(item IndexableItem) []string {
+	var tokens []string
+	for _, value := range item.GetValuesForIndexing() {
+		tokens = append(tokens, strings.ToUpper(value))
+	}
+	return tokens
+}
+
+func (upperTokenizer) NormalizeString(value string) string { return strings.ToUpper(value) }
+
+func TestIndex_Analyze(t *testing.T) {
+	tests := []struct {
+		tokenizer Tokenizer
+		value     string
+		want      []TokenInfo
+	}{
+		{
+			tokenizer: &DefaultTokenizer{},
+			value:     "My_Var (x)",
+			want: []TokenInfo{
+				{Text: "my var (x)", Position: 0, Variation: TokenVariationUnderscore},
+				{Text: "var (x)", Position: 1, Variation: TokenVariationUnderscore},
+				{Text: "(x)", Position: 2, Variation: TokenVariationWordSuffix},
+				{Text: "my var x", Position: 0, Variation: TokenVariationBracketStripped},
+				{Text: "var x", Position: 1, Variation: TokenVariationBracketStripped},
+			},
+		},
+		{
+			tokenizer: NewNGramTokenizer(nil, 2, 3, NGramEdge),
+			value:     "ab-12 cd",
+			want: []TokenInfo{
+				{Text: "ab-12 cd", Position: 0, Variation: TokenVariationWordSuffix},
+				{Text: "cd", Position: 1, Variation: TokenVariationWordSuffix},
+				{Text: "ab 12 cd", Position: 0, Variation: TokenVariationDashless},
+				{Text: "12 cd", Position: 0, Variation: TokenVariationDashless},
+				{Text: nGramNamespace + "ab", Position: 0, Variation: TokenVariationNGram},
+				{Text: nGramNamespace + "12", Position: 0, Variation: TokenVariationNGram},
+				{Text: nGramNamespace + "cd", Position: 1, Variation: TokenVariationNGram},
+			},
+		},
+		{
+			tokenizer: NewCJKTokenizer(nil),
+			value:     "go 東京",
+			want: []TokenInfo{
+				{Text: "go 東京", Position: 0, Variation: TokenVariationWordSuffix},
+				{Text: "東京", Position: 1, Variation: TokenVariationWordSuffix},
+				{Text: "東", Position: 1, Variation: TokenVariationCJKGram},
+				{Text: "京", Position: 1, Variation: TokenVariationCJKGram},
+			},
+		},
+		{
+			tokenizer: NewStemmingTokenizer(nil, nil),
+			value:     "running shoes",
+			want: []TokenInfo{
+				{Text: "running shoes", Position: 0, Variation: TokenVariationWordSuffix},
+				{Text: "shoes", Position: 1, Variation: TokenVariationWordSuffix},
+				{Text: "run shoe", Position: 0, Variation: TokenVariationStemmed},
+				{Text: "shoe", Position: 1, Variation: TokenVariationStemmed},
+			},
+		},
+		{
+			tokenizer: &CodeTokenizer{},
+			value:     "parseURL",
+			want: []TokenInfo{
+				{Text: "parse url", Position: 0, Variation: TokenVariationWordSuffix},
+				{Text: "url", Position: 1, Variation: TokenVariationWordSuffix},
+				{Text: "parseurl", Position: 0, Variation: TokenVariationIdentifierSuffix},
+				{Text: "pu", Position: 0, Variation: TokenVariationInitialism},
+			},
+		},
+		{
+			tokenizer: upperTokenizer{},
+			value:     "abc",
+			want:      []TokenInfo{{Text: "ABC", Position: 0, Variation: TokenVariationUnknown}},
+		},
+	}
+	for _, tt := range tests {
+		index := New[*ExampleItem](WithTokenizer(tt.tokenizer))
+		if got := index.Analyze(tt.value); !slices.Equal(got, tt.want) {
+			t.Errorf("Analyze(%q) with %T = %v, want %v", tt.value, tt.tokenizer, got, tt.want)
+		}
+	}
+}
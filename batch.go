@@ -0,0 +1,61 @@
+package lodestar
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// BatchResult holds the results of a single query in a batch.
+type BatchResult[T IndexableItem] struct {
+	Query   string
+	Results []T
+	Timing  QueryTimingInfo
+}
+
+// PrefixSearchBatch performs a prefix search for each query against the same snapshot of the index,
+// reusing pooled search buffers across queries and fanning out over up to the configured batch
+// concurrency goroutines (see WithBatchConcurrency).
+// Results are returned in the same order as the queries, each with its own timing information.
+// The limit, filter function and search options are applied to each query as in PrefixSearch, so the filter function
+// must be safe for concurrent use when the batch concurrency is greater than 1.
+func (idx *Index[T]) PrefixSearchBatch(queries []string, limit int, filterFn ResultFilterFn[T], opts ...SearchOption[T]) []BatchResult[T] {
+	results := make([]BatchResult[T], len(queries))
+	if len(queries) == 0 {
+		return results
+	}
+	options := newSearchOptions(opts)
+
+	// Workers claim the next query from a shared counter so uneven queries balance out
+	var next atomic.Int64
+
+	workers := min(idx.batchConcurrency, len(queries))
+	if workers <= 1 {
+		idx.searchBatchWorker(queries, limit, filterFn, options, results, &next)
+		return results
+	}
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			idx.searchBatchWorker(queries, limit, filterFn, options, results, &next)
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// searchBatchWorker runs queries with a single set of search buffers, writing into the results slice.
+// Queries are claimed from the shared counter until none are left.
+func (idx *Index[T]) searchBatchWorker(queries []string, limit int, filterFn ResultFilterFn[T], options *searchOptions[T], results []BatchResult[T], next *atomic.Int64) {
+	buffers := idx.getSearchBuffers()
+	defer idx.putSearchBuffers(buffers)
+
+	for i := int(next.Add(1) - 1); i < len(queries); i = int(next.Add(1) - 1) {
+		items, timing := idx.prefixSearch(queries[i], limit, filterFn, options, buffers)
+		results[i] = BatchResult[T]{Query: queries[i], Results: items, Timing: timing}
+		buffers.reset()
+	}
+}
@@ -0,0 +1,192 @@
+package lodestar
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CJKTokenizer wraps a Tokenizer to support Chinese, Japanese and Korean text, which is not separated
+// by spaces. Runs of Han, Hiragana, Katakana and Hangul characters are indexed as overlapping bigrams
+// and unigrams, alongside the tokens of the wrapped tokenizer, which still splits Latin text on whitespace.
+//
+// Queries of up to two CJK characters match the bigrams and unigrams directly. Longer queries are
+// searched by their first bigram, keeping the items with a value that contains every CJK run and word
+// of the query, so that a query matches anywhere in a CJK run.
+type CJKTokenizer struct {
+	tokenizerWrapper
+}
+
+// NewCJKTokenizer creates a CJKTokenizer wrapping the tokenizer.
+// If tokenizer is nil, the DefaultTokenizer is used.
+func NewCJKTokenizer(tokenizer Tokenizer) *CJKTokenizer {
+	if tokenizer == nil {
+		tokenizer = &DefaultTokenizer{}
+	}
+	return &CJKTokenizer{tokenizerWrapper: tokenizerWrapper{Tokenizer: tokenizer}}
+}
+
+func (t *CJKTokenizer) Tokenize(item IndexableItem) []string {
+	tokens := t.Tokenizer.Tokenize(item)
+	for _, value := range item.GetValuesForIndexing() {
+		if grams := cjkGrams(t.Tokenizer.NormalizeString(value)); grams != nil {
+			tokens = mergeUniqueTokens(tokens, grams)
+		}
+	}
+	return tokens
+}
+
+// queryForms returns the forms of the wrapped tokenizer, and the first bigram of queries that are
+// longer than a bigram, matching the items that contain the whole query.
+func (t *CJKTokenizer) queryForms(normalized string) []queryForm {
+	forms := t.tokenizerWrapper.queryForms(normalized)
+
+	runs := cjkRuns(normalized)
+	if len(runs) == 0 || (len(runs) == 1 && runs[0] == normalized && utf8.RuneCountInString(normalized) <= 2) {
+		// Short queries match the grams as they are
+		return forms
+	}
+
+	// Search the first bigram, or the first unigram if all runs are single characters
+	first := runs[0]
+	for _, run := range runs {
+		if utf8.RuneCountInString(run) >= 2 {
+			first = run
+			break
+		}
+	}
+	_, size := utf8.DecodeRuneInString(first)
+	if len(first) > size {
+		_, next := utf8.DecodeRuneInString(first[size:])
+		size += next
+	}
+
+	segments := cjkSegments(normalized)
+	return append(forms, queryForm{
+		prefix: first[:size],
+		factor: 1,
+		match: func(item IndexableItem) bool {
+			for _, value := range item.GetValuesForIndexing() {
+				if containsAll(t.Tokenizer.NormalizeString(value), segments) {
+					return true
+				}
+			}
+			return false
+		},
+	})
+}
+
+// Analyze returns the tokens of the wrapped tokenizer, followed by the grams of the CJK runs.
+func (t *CJKTokenizer) Analyze(value string) []TokenInfo {
+	tokens := t.tokenizerWrapper.Analyze(value)
+	tokens = append(tokens, cjkGramTokens(t.Tokenizer.NormalizeString(value))...)
+	return uniqueTokenInfos(tokens)
+}
+
+// tokenVariation returns the rule of the wrapped tokenizer that produced the token, or
+// TokenVariationCJKGram if it is a bigram or unigram of a CJK run.
+func (t *CJKTokenizer) tokenVariation(value string, token string) TokenVariation {
+	if variation := t.tokenizerWrapper.tokenVariation(value, token); variation != TokenVariationUnknown {
+		return variation
+	}
+	for _, gram := range cjkGrams(t.Tokenizer.NormalizeString(value)) {
+		if gram == token {
+			return TokenVariationCJKGram
+		}
+	}
+	return TokenVariationUnknown
+}
+
+// isCJK returns true if the rune belongs to a script that is written without spaces.
+func isCJK(r rune) bool {
+	// The prolonged sound marks are common to Hiragana and Katakana
+	if r == 'ー' || r == 'ｰ' {
+		return true
+	}
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// cjkRuns returns the runs of consecutive CJK characters in the value.
+func cjkRuns(value string) []string {
+	var runs []string
+	start := -1
+	for i, r := range value {
+		if isCJK(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			runs = append(runs, value[start:i])
+			start = -1
+		}
+	}
+	if start >= 0 {
+		runs = append(runs, value[start:])
+	}
+	return runs
+}
+
+// cjkSegments splits the value into its CJK runs and the whitespace separated words between them.
+func cjkSegments(value string) []string {
+	var segments []string
+	start, startCJK := -1, false
+	for i, r := range value {
+		cjk := isCJK(r)
+		if start >= 0 && (unicode.IsSpace(r) || cjk != startCJK) {
+			segments = append(segments, value[start:i])
+			start = -1
+		}
+		if start < 0 && !unicode.IsSpace(r) {
+			start, startCJK = i, cjk
+		}
+	}
+	if start >= 0 {
+		segments = append(segments, value[start:])
+	}
+	return segments
+}
+
+// cjkGrams returns the unigrams and overlapping bigrams of the CJK runs in the value.
+func cjkGrams(value string) []string {
+	grams := cjkGramTokens(value)
+	if grams == nil {
+		return nil
+	}
+	return tokenTexts(grams)
+}
+
+// cjkGramTokens returns the unigrams and overlapping bigrams of the CJK runs in the value, positioned at
+// the whitespace separated word containing them.
+func cjkGramTokens(value string) []TokenInfo {
+	var grams []TokenInfo
+	position, inWord := -1, false
+	for i, r := range value {
+		if unicode.IsSpace(r) {
+			inWord = false
+			continue
+		}
+		if !inWord {
+			position, inWord = position+1, true
+		}
+		if !isCJK(r) {
+			continue
+		}
+		size := utf8.RuneLen(r)
+		grams = append(grams, TokenInfo{Text: value[i : i+size], Position: position, Variation: TokenVariationCJKGram})
+		if next, nextSize := utf8.DecodeRuneInString(value[i+size:]); isCJK(next) {
+			grams = append(grams, TokenInfo{Text: value[i : i+size+nextSize], Position: position, Variation: TokenVariationCJKGram})
+		}
+	}
+	return grams
+}
+
+func containsAll(value string, segments []string) bool {
+	for _, segment := range segments {
+		if !strings.Contains(value, segment) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,50 @@
+package lodestar
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCJKTokenizer(t *testing.T) {
+	tr := NewCJKTokenizer(nil)
+
+	got := tr.Tokenize(&valueItem{value: "東京タワー Tokyo"})
+	want := []string{"tokyo", "タ", "タワ", "ワ", "ワー", "ー", "京", "京タ", "東", "東京", "東京タワー tokyo"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Tokenize() = %v, want %v", got, want)
+	}
+
+	if got := tr.tokenVariation("東京タワー", "京タ"); got != TokenVariationCJKGram {
+		t.Errorf("tokenVariation() = %v, want %v", got, TokenVariationCJKGram)
+	}
+	if got := cjkSegments("tokyo 東京タワー見学 tour"); !slices.Equal(got, []string{"tokyo", "東京タワー見学", "tour"}) {
+		t.Errorf("cjkSegments() = %v", got)
+	}
+}
+
+func TestPrefixSearchCJK(t *testing.T) {
+	items := []*ExampleItem{
+		{Text: "東京タワー", Rank: 10},
+		{Text: "京都タワー", Rank: 8},
+		{Text: "東京スカイツリー", Rank: 6},
+		{Text: "서울 타워", Rank: 4},
+		{Text: "Tokyo Tower", Rank: 2},
+	}
+	index, _ := New[*ExampleItem](WithTokenizer(NewCJKTokenizer(nil))).IndexItems(items)
+
+	for query, want := range map[string][]string{
+		"東京":    {"東京タワー", "東京スカイツリー"},
+		"タワー":   {"東京タワー", "京都タワー"},
+		"京":     {"東京タワー", "京都タワー", "東京スカイツリー"},
+		"スカイツリ": {"東京スカイツリー"},
+		"京タワー":  {"東京タワー"},
+		"타워":    {"서울 타워"},
+		"tower": {"Tokyo Tower"},
+	} {
+		results, _ := index.PrefixSearch(query, 0, nil)
+		got := resultTexts(results)
+		if !slices.Equal(got, want) {
+			t.Errorf("PrefixSearch(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
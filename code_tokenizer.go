@@ -0,0 +1,132 @@
+package lodestar
+
+import (
+	"log/slog"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CodeTokenizer implements the Tokenizer interface for code symbols, file paths and config keys.
+// Values are split into words on camelCase and PascalCase boundaries, keeping acronyms together
+// (e.g. "HTTPServerConfig" into "http", "server" and "config"), and on underscores, hyphens, dots,
+// slashes and other punctuation.
+//
+// For every word boundary, it indexes the suffix of the words joined by spaces (e.g. "server config")
+// and joined without separators (e.g. "serverconfig", or "httpgo" for "pkg/net/http.go"), as well as
+// the initialism of values with several words (e.g. "hsc").
+// Queries are split into words the same way and joined by spaces.
+type CodeTokenizer struct{}
+
+func (t *CodeTokenizer) Tokenize(item IndexableItem) []string {
+	var allTokens []string
+	for _, value := range item.GetValuesForIndexing() {
+		tokens := t.tokenizeString(value)
+		if tokens != nil {
+			allTokens = mergeUniqueTokens(allTokens, tokens)
+		} else {
+			slog.Warn("Tokenization for item value returned an empty set", "value", value, "item", item)
+		}
+	}
+	return allTokens
+}
+
+func (t *CodeTokenizer) tokenizeString(value string) []string {
+	words := splitIdentifier(value)
+	if len(words) == 0 {
+		return nil
+	}
+	return mergeUniqueTokens(
+		computePrefixCombinations(words),
+		identifierSuffixes(words),
+		identifierInitialism(words),
+	)
+}
+
+// NormalizeString splits the value into lowercase words like the values are split, joined by spaces.
+func (t *CodeTokenizer) NormalizeString(value string) string {
+	return strings.Join(splitIdentifier(value), " ")
+}
+
+// Analyze returns the tokens of the value with the rule that produced them and the index of the word
+// they start at.
+func (t *CodeTokenizer) Analyze(value string) []TokenInfo {
+	words := splitIdentifier(value)
+	var tokens []TokenInfo
+	for i, suffix := range computePrefixCombinations(words) {
+		tokens = append(tokens, TokenInfo{Text: suffix, Position: i, Variation: TokenVariationWordSuffix})
+	}
+	for i, suffix := range identifierSuffixes(words) {
+		tokens = append(tokens, TokenInfo{Text: suffix, Position: i, Variation: TokenVariationIdentifierSuffix})
+	}
+	for _, initialism := range identifierInitialism(words) {
+		tokens = append(tokens, TokenInfo{Text: initialism, Variation: TokenVariationInitialism})
+	}
+	return uniqueTokenInfos(tokens)
+}
+
+// tokenVariation returns the rule that produced the token from the value.
+func (t *CodeTokenizer) tokenVariation(value string, token string) TokenVariation {
+	return tokenInfoVariation(t.Analyze(value), token)
+}
+
+// splitIdentifier splits the value into lowercase words on case changes and on all characters other than
+// letters and digits.
+// A run of upper case letters is kept together as an acronym, except for its last letter if it starts
+// a capitalized word, e.g. "HTTPServer" is split into "http" and "server". Digits belong to the preceding word.
+func splitIdentifier(value string) []string {
+	var words []string
+	start := -1
+	var prev rune
+
+	flush := func(end int) {
+		if start >= 0 {
+			words = append(words, strings.ToLower(value[start:end]))
+			start = -1
+		}
+	}
+
+	for i, r := range value {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush(i)
+			prev = r
+			continue
+		}
+		if start >= 0 && unicode.IsUpper(r) {
+			next, _ := utf8.DecodeRuneInString(value[i+utf8.RuneLen(r):])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && unicode.IsLower(next)) {
+				// Start of a new capitalized word, or the end of an acronym
+				flush(i)
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+		prev = r
+	}
+	flush(len(value))
+	return words
+}
+
+// identifierSuffixes returns the suffixes of the words starting at each word, joined without separators,
+// so that they are matched by queries normalized to a single word.
+func identifierSuffixes(words []string) []string {
+	suffixes := make([]string, 0, len(words))
+	for i := range words {
+		suffixes = append(suffixes, strings.Join(words[i:], ""))
+	}
+	return suffixes
+}
+
+// identifierInitialism returns the first letters of the words if there are several words.
+func identifierInitialism(words []string) []string {
+	if len(words) < 2 {
+		return nil
+	}
+	var b strings.Builder
+	for _, word := range words {
+		r, _ := utf8.DecodeRuneInString(word)
+		b.WriteRune(r)
+	}
+	return []string{b.String()}
+}
@@ -0,0 +1,74 @@
+package lodestar
+
+import (
+	"slices"
+	"testing"
+)
+
+func Test_splitIdentifier(t *testing.T) {
+	tests := map[string][]string{
+		"HTTPServerConfig":  {"http", "server", "config"},
+		"parseURL":          {"parse", "url"},
+		"utf8Decode":        {"utf8", "decode"},
+		"Base64":            {"base64"},
+		"MAX_RETRY_COUNT":   {"max", "retry", "count"},
+		"pkg/net/http.go":   {"pkg", "net", "http", "go"},
+		"log-level.default": {"log", "level", "default"},
+		"IOReader":          {"io", "reader"},
+		"":                  nil,
+	}
+	for value, want := range tests {
+		if got := splitIdentifier(value); !slices.Equal(got, want) {
+			t.Errorf("splitIdentifier(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestCodeTokenizer(t *testing.T) {
+	tr := &CodeTokenizer{}
+
+	got := tr.Tokenize(&valueItem{value: "HTTPServerConfig"})
+	want := []string{"config", "hsc", "http server config", "httpserverconfig", "server config", "serverconfig"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Tokenize() = %q, want %q", got, want)
+	}
+
+	got = tr.Tokenize(&valueItem{value: "pkg/net/http.go"})
+	want = []string{"go", "http go", "httpgo", "net http go", "nethttpgo", "pkg net http go", "pkgnethttpgo", "pnhg"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Tokenize() = %q, want %q", got, want)
+	}
+
+	if got := tr.NormalizeString(" ServerConf "); got != "server conf" {
+		t.Errorf("NormalizeString() = %q", got)
+	}
+	if got := tr.tokenVariation("HTTPServerConfig", "hsc"); got != TokenVariationInitialism {
+		t.Errorf("tokenVariation() = %v, want %v", got, TokenVariationInitialism)
+	}
+
+	items := []*ExampleItem{
+		{Text: "HTTPServerConfig", Rank: 3},
+		{Text: "pkg/net/http.go", Rank: 2},
+		{Text: "max_retry_count", Rank: 1},
+	}
+	index, _ := New[*ExampleItem](WithTokenizer(tr)).IndexItems(items)
+	for query, want := range map[string][]string{
+		"server config": {"HTTPServerConfig"},
+		"hsc":           {"HTTPServerConfig"},
+		"ServerCon":     {"HTTPServerConfig"},
+		"serverco":      {"HTTPServerConfig"},
+		"http":          {"HTTPServerConfig", "pkg/net/http.go"},
+		"net/http":      {"pkg/net/http.go"},
+		"http.go":       {"pkg/net/http.go"},
+		"httpgo":        {"pkg/net/http.go"},
+		"nethttp":       {"pkg/net/http.go"},
+		"retry_c":       {"max_retry_count"},
+		"RetryCount":    {"max_retry_count"},
+	} {
+		results, _ := index.PrefixSearch(query, 0, nil)
+		got := resultTexts(results)
+		if !slices.Equal(got, want) {
+			t.Errorf("PrefixSearch(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
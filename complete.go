@@ -0,0 +1,120 @@
+package lodestar
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// completionKeyReplacer maps bracket and hyphen variants of a completion onto the same key.
+var completionKeyReplacer = strings.NewReplacer("(", "", ")", "", "[", "", "]", "", "{", "", "}", "", "-", " ")
+
+// Completion represents a text completion of a prefix up to the next word boundary.
+type Completion struct {
+	// Text is the completed text, e.g. "quick brown" for the prefix "quick b"
+	Text string
+
+	// ItemCount is the number of unique items indexed under tokens with this completion
+	ItemCount int
+
+	// MaxRank is the highest rank of the items covered by the completion
+	MaxRank int
+
+	// TotalRank is the sum of the ranks of the unique items covered by the completion
+	TotalRank int
+}
+
+// completionAggregate accumulates the items covered by a completion.
+type completionAggregate struct {
+	text  string
+	ranks map[any]int
+}
+
+// Complete returns up to n distinct text completions of the prefix, each extending up to the next
+// word boundary (whitespace or hyphen), e.g. "quick brown" for "quick b".
+// The prefix is normalized before searching.
+// Completions that only differ by brackets or hyphens are deduplicated.
+// Results are sorted by the highest rank of their items, then by their total rank (descending).
+// If n <= 0, all completions are returned.
+func (idx *Index[T]) Complete(prefix string, n int) []Completion {
+	prefix = idx.tokenizer.NormalizeString(prefix)
+	if prefix == "" {
+		return nil
+	}
+
+	aggregates := make(map[string]*completionAggregate)
+	var keys []string
+
+	idx.index.Root().WalkPrefix([]byte(prefix), func(token []byte, postings []posting[T]) bool {
+		text := completeToWordBoundary(string(token), len(prefix))
+		key := completionKeyReplacer.Replace(text)
+
+		aggregate, found := aggregates[key]
+		if !found {
+			aggregate = &completionAggregate{text: text, ranks: make(map[any]int)}
+			aggregates[key] = aggregate
+			keys = append(keys, key)
+		} else if text == key {
+			// Prefer the plain variant for display
+			aggregate.text = text
+		}
+
+		for _, p := range postings {
+			aggregate.ranks[p.item.GetID()] = p.item.GetRank()
+		}
+		return false
+	})
+
+	completions := make([]Completion, 0, len(keys))
+	for _, key := range keys {
+		aggregate := aggregates[key]
+		completion := Completion{
+			Text:      aggregate.text,
+			ItemCount: len(aggregate.ranks),
+		}
+		first := true
+		for _, rank := range aggregate.ranks {
+			if first || rank > completion.MaxRank {
+				completion.MaxRank = rank
+				first = false
+			}
+			completion.TotalRank += rank
+		}
+		completions = append(completions, completion)
+	}
+
+	slices.SortFunc(completions, func(a, b Completion) int {
+		return cmp.Or(
+			cmp.Compare(b.MaxRank, a.MaxRank),
+			cmp.Compare(b.TotalRank, a.TotalRank),
+			cmp.Compare(a.Text, b.Text),
+		)
+	})
+
+	if n > 0 && len(completions) > n {
+		completions = completions[:n]
+	}
+	return completions
+}
+
+// completeToWordBoundary truncates the token at the first word boundary after the cursor position.
+// A boundary directly at the cursor is skipped so that a completed word is extended by the next word.
+func completeToWordBoundary(token string, cursor int) string {
+	if cursor >= len(token) {
+		return token
+	}
+	start := cursor
+	if isCompletionBoundary(token[start]) {
+		start++
+	}
+	if end := strings.IndexFunc(token[start:], func(r rune) bool {
+		return r < 0x80 && isCompletionBoundary(byte(r))
+	}); end >= 0 {
+		return token[:start+end]
+	}
+	return token
+}
+
+func isCompletionBoundary(c byte) bool {
+	return c == ' ' || c == '-'
+}
@@ -0,0 +1,38 @@
+package lodestar
+
+import (
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	index := setupIndexWithItems(testItems2)
+
+	completions := index.Complete("the q", 0)
+	if len(completions) != 1 {
+		t.Fatalf("Expected 1 completion for 'the q', got %+v", completions)
+	}
+	if completions[0].Text != "the quick" || completions[0].ItemCount != 2 || completions[0].MaxRank != 25 {
+		t.Errorf("Unexpected completion for 'the q': %+v", completions[0])
+	}
+
+	// Hyphenated and dashless variants collapse into a single completion
+	completions = index.Complete("quick brown", 0)
+	got := make([]string, 0, len(completions))
+	for _, c := range completions {
+		got = append(got, c.Text)
+	}
+	want := []string{"quick brown dog", "quick brown fox"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Complete('quick brown') got %v, want %v", got, want)
+	}
+
+	// Bracket variants collapse into a single completion
+	completions = index.Complete("the", 1)
+	if len(completions) != 1 || completions[0].Text != "the quick" || completions[0].TotalRank != 45 {
+		t.Errorf("Unexpected completions for 'the': %+v", completions)
+	}
+
+	if completions := index.Complete("zebra", 0); len(completions) != 0 {
+		t.Errorf("Expected no completions, got %+v", completions)
+	}
+}
@@ -0,0 +1,114 @@
+package lodestar
+
+import (
+	"container/heap"
+	"math"
+)
+
+// diversityPoolFactor is how many more candidates than the limit are collected for diversification.
+const diversityPoolFactor = 4
+
+// diversityPoolLimit returns the number of candidates to collect for diversifying the top limit results.
+func diversityPoolLimit(limit int) int {
+	if limit > math.MaxInt/diversityPoolFactor {
+		return math.MaxInt
+	}
+	return limit * diversityPoolFactor
+}
+
+// diversify greedily selects up to limit results from the pool ordered by score, scoring each candidate
+// by its normalized score and penalizing it by the number of already selected results sharing its key.
+// Ties are broken by the position in the pool. Results that are not selected are reported to the trace.
+func diversify[T IndexableItem](pool []result[T], limit int, keyFn func(item T) any, lambda float64, trace *searchTrace[T]) []result[T] {
+	if len(pool) == 0 {
+		return pool
+	}
+
+	// Normalize scores to [0, 1] over the pool so that lambda is independent of the rank scale
+	minScore, maxScore := pool[0].Score, pool[0].Score
+	for _, r := range pool {
+		minScore = min(minScore, r.Score)
+		maxScore = max(maxScore, r.Score)
+	}
+	relevance := func(r result[T]) float64 {
+		if maxScore == minScore {
+			return 1
+		}
+		return (r.Score - minScore) / (maxScore - minScore)
+	}
+
+	// The best candidate of a key is its first unselected result in the pool, as the pool is ordered
+	// by score and all results of a key share its penalty. Only the score of the key of the selected
+	// candidate changes, so the keys are kept in a heap by the score of their best candidate.
+	groups := make(map[any]*diversityGroup)
+	candidates := make(diversityHeap, 0)
+	for i, r := range pool {
+		key := keyFn(r.Value)
+		group, found := groups[key]
+		if !found {
+			group = &diversityGroup{}
+			groups[key] = group
+			candidates = append(candidates, group)
+		}
+		group.positions = append(group.positions, i)
+	}
+	for _, group := range candidates {
+		group.score = lambda * relevance(pool[group.positions[0]])
+	}
+	heap.Init(&candidates)
+
+	selected := make([]bool, len(pool))
+	results := make([]result[T], 0, min(limit, len(pool)))
+	for len(results) < limit && len(candidates) > 0 {
+		group := candidates[0]
+		best := group.positions[group.next]
+		selected[best] = true
+		results = append(results, pool[best])
+
+		group.next++
+		group.selected++
+		if group.next == len(group.positions) {
+			heap.Pop(&candidates)
+			continue
+		}
+		group.score = lambda*relevance(pool[group.positions[group.next]]) - (1-lambda)*float64(group.selected)
+		heap.Fix(&candidates, 0)
+	}
+
+	for i, r := range pool {
+		if !selected[i] {
+			trace.evict(r.Value, CutDiversity)
+		}
+	}
+	return results
+}
+
+// diversityGroup holds the results of the pool sharing a diversity key.
+type diversityGroup struct {
+	// Positions of the results in the pool, and the index of the next unselected one
+	positions []int
+	next      int
+
+	// Number of selected results, and the diversified score of the next unselected result
+	selected int
+	score    float64
+}
+
+// diversityHeap is a max-heap of groups by the score of their next result, then by its pool position.
+type diversityHeap []*diversityGroup
+
+func (h diversityHeap) Len() int { return len(h) }
+func (h diversityHeap) Less(i, j int) bool {
+	if h[i].score != h[j].score {
+		return h[i].score > h[j].score
+	}
+	return h[i].positions[h[i].next] < h[j].positions[h[j].next]
+}
+func (h diversityHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *diversityHeap) Push(x any)   { *h = append(*h, x.(*diversityGroup)) }
+func (h *diversityHeap) Pop() any {
+	old := *h
+	group := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return group
+}
@@ -0,0 +1,84 @@
+package lodestar
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestPrefixSearchDiversity(t *testing.T) {
+	var items []*categoryItem
+	for i := range 6 {
+		items = append(items, &categoryItem{ExampleItem: ExampleItem{Text: fmt.Sprintf("ap brand-a %d", i), Rank: 100 - i}, Category: "a"})
+	}
+	items = append(items,
+		&categoryItem{ExampleItem: ExampleItem{Text: "ap brand-b 0", Rank: 50}, Category: "b"},
+		&categoryItem{ExampleItem: ExampleItem{Text: "ap brand-b 1", Rank: 49}, Category: "b"},
+		&categoryItem{ExampleItem: ExampleItem{Text: "ap brand-c 0", Rank: 10}, Category: "c"},
+	)
+	index, _ := New[*categoryItem]().IndexItems(items)
+
+	tests := []struct {
+		lambda float64
+		want   []string
+	}{
+		{lambda: 1, want: []string{"ap brand-a 0", "ap brand-a 1", "ap brand-a 2"}},
+		{lambda: 0.7, want: []string{"ap brand-a 0", "ap brand-a 1", "ap brand-b 0"}},
+		{lambda: 0, want: []string{"ap brand-a 0", "ap brand-b 0", "ap brand-c 0"}},
+	}
+	for _, tt := range tests {
+		results, _ := index.PrefixSearch("ap", 3, nil, WithDiversity[*categoryItem](nil, tt.lambda))
+		if got := resultTexts(results); !slices.Equal(got, tt.want) {
+			t.Errorf("Diversified search with lambda %v got %v, want %v", tt.lambda, got, tt.want)
+		}
+	}
+}
+
+func TestPrefixSearchDiversityDominantKey(t *testing.T) {
+	// The apex items fill more than the pool of 4 * limit candidates on their own
+	var items []*categoryItem
+	for i := range 50 {
+		items = append(items, &categoryItem{ExampleItem: ExampleItem{Text: fmt.Sprintf("ap apex %d", i), Rank: 1000 - i}, Category: "apex"})
+	}
+	for i := range 5 {
+		items = append(items, &categoryItem{ExampleItem: ExampleItem{Text: fmt.Sprintf("ap apple %d", i), Rank: 10 - i}, Category: "apple"})
+	}
+	index, _ := New[*categoryItem]().IndexItems(items)
+
+	results, _ := index.PrefixSearch("ap", 10, nil, WithDiversity[*categoryItem](nil, 0))
+	want := []string{
+		"ap apex 0", "ap apple 0", "ap apex 1", "ap apple 1", "ap apex 2",
+		"ap apple 2", "ap apex 3", "ap apple 3", "ap apex 4", "ap apple 4",
+	}
+	if got := resultTexts(results); !slices.Equal(got, want) {
+		t.Errorf("Diversified search got %v, want %v", got, want)
+	}
+
+	// The candidates left out of the pool are reported as cut by diversity
+	explanation := index.Explain("ap", 10, nil, WithDiversity[*categoryItem](nil, 0))
+	diversityCuts := 0
+	for _, c := range explanation.Cuts {
+		switch c.Reason {
+		case CutDiversity:
+			diversityCuts++
+		case CutDuplicate:
+		default:
+			t.Errorf("Unexpected cut %+v", c)
+		}
+	}
+	if diversityCuts == 0 {
+		t.Errorf("Expected diversity cuts, got %+v", explanation.Cuts)
+	}
+}
+
+func BenchmarkPrefixSearchDiversityUnlimited(b *testing.B) {
+	var items []*categoryItem
+	for i := range 20000 {
+		items = append(items, &categoryItem{ExampleItem: ExampleItem{Text: fmt.Sprintf("ap item %d", i), Rank: i}, Category: fmt.Sprint(i % 50)})
+	}
+	index, _ := New[*categoryItem]().IndexItems(items)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		index.PrefixSearch("ap", 0, nil, WithDiversity[*categoryItem](nil, 0.7))
+	}
+}
@@ -0,0 +1,233 @@
+package lodestar
+
+import (
+	"slices"
+)
+
+// CutReason describes why a matching candidate was left out of the results.
+type CutReason string
+
+const (
+	// CutDuplicate means the item was already seen under another token.
+	CutDuplicate CutReason = "duplicate"
+	// CutFiltered means the item was rejected by the filter function.
+	CutFiltered CutReason = "filtered"
+	// CutLimit means the item was pushed out of the top results by a higher ranked item.
+	CutLimit CutReason = "limit"
+	// CutGroupLimit means the item was left out because its group already had enough higher ranked items.
+	CutGroupLimit CutReason = "group-limit"
+	// CutDiversity means the item was a candidate but was displaced by diversification.
+	CutDiversity CutReason = "diversity"
+)
+
+// TokenVariation describes which tokenizer rule created a token from an indexed value.
+type TokenVariation string
+
+const (
+	// TokenVariationUnknown is used when the tokenizer cannot explain its tokens.
+	TokenVariationUnknown TokenVariation = "unknown"
+	// TokenVariationWord is a single word of the value, as split by an Analyzer.
+	TokenVariationWord TokenVariation = "word"
+	// TokenVariationWordSuffix is a combination of words from a word boundary to the end of the value.
+	TokenVariationWordSuffix TokenVariation = "word-suffix"
+	// TokenVariationUnderscore is a word suffix of the value starting at a word that was joined by underscores.
+	TokenVariationUnderscore TokenVariation = "underscore"
+	// TokenVariationDashless is a word suffix of the value with hyphens replaced by spaces.
+	TokenVariationDashless TokenVariation = "dashless"
+	// TokenVariationBracketStripped is a token with brackets removed.
+	TokenVariationBracketStripped TokenVariation = "bracket-stripped"
+	// TokenVariationStemmed is a token of the value with its words stemmed.
+	TokenVariationStemmed TokenVariation = "stemmed"
+	// TokenVariationSynonym is a token with its terms replaced by synonyms when indexing.
+	TokenVariationSynonym TokenVariation = "synonym"
+	// TokenVariationPhonetic is the phonetic codes of the words of a token, indexed in the phonetic namespace.
+	TokenVariationPhonetic TokenVariation = "phonetic"
+	// TokenVariationFolded is a token of the value with its case folded and accents stripped.
+	TokenVariationFolded TokenVariation = "folded"
+	// TokenVariationCJKGram is a unigram or bigram of a run of CJK characters.
+	TokenVariationCJKGram TokenVariation = "cjk-gram"
+	// TokenVariationNGram is an n-gram of a word, indexed in the n-gram namespace.
+	TokenVariationNGram TokenVariation = "ngram"
+	// TokenVariationIdentifierSuffix is a suffix of the words of an identifier joined without separators, e.g. "serverconfig".
+	TokenVariationIdentifierSuffix TokenVariation = "identifier-suffix"
+	// TokenVariationInitialism is the first letters of the words of an identifier, e.g. "hsc".
+	TokenVariationInitialism TokenVariation = "initialism"
+)
+
+// Explanation describes how the results of a prefix search were matched and ordered.
+type Explanation[T IndexableItem] struct {
+	// Query is the normalized query
+	Query string
+
+	// Results holds an explanation for each result, in result order
+	Results []ResultExplanation[T]
+
+	// Cuts holds the matching candidates that were left out of the results
+	Cuts []CutCandidate[T]
+
+	// EarlyExits holds the posting lists whose tails were skipped because they could not beat the results
+	EarlyExits []EarlyExit
+
+	Timing QueryTimingInfo
+}
+
+// ResultExplanation describes why an item matched a query.
+type ResultExplanation[T IndexableItem] struct {
+	Item T
+
+	// Token is the indexed token that matched the query
+	Token string
+
+	// Alternative is the alternative of the query that matched the token, e.g. a synonym,
+	// or empty if the query itself matched
+	Alternative string
+
+	// Value is the value from GetValuesForIndexing that produced the token
+	Value string
+
+	// Variation is the tokenizer rule that produced the token from the value
+	Variation TokenVariation
+
+	// Rank is the rank of the item
+	Rank int
+
+	// Weight is the weight of the matched value, 1 for unweighted items
+	Weight float64
+
+	// Boost is the dynamic boost from the rank overlay and learned selections
+	Boost float64
+
+	// Score is the rank combined with the weight and boost, that the item was ordered by
+	Score float64
+}
+
+// CutCandidate describes a matching item that was left out of the results.
+type CutCandidate[T IndexableItem] struct {
+	Item   T
+	Token  string
+	Reason CutReason
+}
+
+// EarlyExit describes a posting list whose remaining items were skipped.
+type EarlyExit struct {
+	Token string
+
+	// Skipped is the number of items in the posting list that were not visited
+	Skipped int
+}
+
+// tokenVariationExplainer is implemented by tokenizers that can tell which rule produced a token.
+type tokenVariationExplainer interface {
+	tokenVariation(value string, token string) TokenVariation
+}
+
+// searchTrace records the aggregation decisions of a prefix search.
+// All methods are no-ops on a nil trace.
+type searchTrace[T IndexableItem] struct {
+	accepted   map[any]result[T]
+	cuts       []CutCandidate[T]
+	earlyExits []EarlyExit
+}
+
+func (t *searchTrace[T]) accept(r result[T]) {
+	if t == nil {
+		return
+	}
+	t.accepted[r.Value.GetID()] = r
+}
+
+func (t *searchTrace[T]) evict(item T, reason CutReason) {
+	if t == nil {
+		return
+	}
+	id := item.GetID()
+	t.cuts = append(t.cuts, CutCandidate[T]{Item: item, Token: t.accepted[id].Token, Reason: reason})
+	delete(t.accepted, id)
+}
+
+func (t *searchTrace[T]) cut(item T, token string, reason CutReason) {
+	if t == nil {
+		return
+	}
+	t.cuts = append(t.cuts, CutCandidate[T]{Item: item, Token: token, Reason: reason})
+}
+
+func (t *searchTrace[T]) earlyExit(token string, skipped int) {
+	if t == nil {
+		return
+	}
+	t.earlyExits = append(t.earlyExits, EarlyExit{Token: token, Skipped: skipped})
+}
+
+// Explain performs a prefix search like PrefixSearch and describes, for each result, the token it
+// matched, the indexed value and tokenizer rule that produced the token and the rank it was ordered by.
+// It also reports the candidates that were cut by deduplication, the filter function, the limit or
+// the group limit or diversification, and the posting lists that were cut short by the early exit.
+// This is intended for debugging and is slower than PrefixSearch.
+func (idx *Index[T]) Explain(query string, limit int, filterFn ResultFilterFn[T], opts ...SearchOption[T]) Explanation[T] {
+	buffers := idx.getSearchBuffers()
+	trace := &searchTrace[T]{accepted: make(map[any]result[T])}
+	buffers.trace = trace
+	defer func() {
+		buffers.trace = nil
+		idx.putSearchBuffers(buffers)
+	}()
+
+	results, timing := idx.prefixSearch(query, limit, filterFn, newSearchOptions(opts), buffers)
+
+	var normalized string
+	if queries := idx.normalizeQuery(query); len(queries) > 0 {
+		normalized = queries[0]
+	}
+	explanation := Explanation[T]{
+		Query:      normalized,
+		Results:    make([]ResultExplanation[T], 0, len(results)),
+		Cuts:       trace.cuts,
+		EarlyExits: trace.earlyExits,
+		Timing:     timing,
+	}
+	for _, item := range results {
+		accepted := trace.accepted[item.GetID()]
+		value, variation := idx.explainToken(item, accepted.Token)
+		explanation.Results = append(explanation.Results, ResultExplanation[T]{
+			Item:        item,
+			Token:       accepted.Token,
+			Alternative: accepted.Query,
+			Value:       value,
+			Variation:   variation,
+			Rank:        accepted.Rank,
+			Weight:      accepted.Weight,
+			Boost:       accepted.Boost,
+			Score:       accepted.Score,
+		})
+	}
+	return explanation
+}
+
+// Analyze returns the tokens the tokenizer of the index produces from the value, like the tokens
+// indexed for an item with the value. Each token has the rule that produced it and the position of the
+// word it starts at. This is intended for debugging the contents of the index.
+func (idx *Index[T]) Analyze(value string) []TokenInfo {
+	return analyzeValue(idx.tokenizer, value)
+}
+
+// explainToken finds the value of the item that produced the token, and the rule that produced it.
+func (idx *Index[T]) explainToken(item T, token string) (string, TokenVariation) {
+	values := item.GetValuesForIndexing()
+	if weightedItem, ok := any(item).(WeightedIndexableItem); ok {
+		values = values[:0:0]
+		for _, value := range weightedItem.GetWeightedValuesForIndexing() {
+			values = append(values, value.Text)
+		}
+	}
+	for _, value := range values {
+		if !slices.Contains(idx.tokenizer.Tokenize(&valueItem{value: value}), token) {
+			continue
+		}
+		if explainer, ok := idx.tokenizer.(tokenVariationExplainer); ok {
+			return value, explainer.tokenVariation(value, token)
+		}
+		return value, TokenVariationUnknown
+	}
+	return "", TokenVariationUnknown
+}
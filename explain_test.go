@@ -0,0 +1,50 @@
+package lodestar
+
+import (
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	index := setupIndexWithItems(testItems2)
+
+	explanation := index.Explain("Brown", 2, func(_ string, _ string, item *ExampleItem) bool {
+		return item.Text != "the very-slow brown fox"
+	})
+	if explanation.Query != "brown" {
+		t.Errorf("Expected normalized query 'brown', got %q", explanation.Query)
+	}
+
+	want := []ResultExplanation[*ExampleItem]{
+		{Item: testItems2[3], Token: "brown dog", Value: "the quick brown-dog", Variation: TokenVariationDashless, Rank: 25, Weight: 1, Score: 25},
+		{Item: testItems2[0], Token: "brown fox", Value: "the quick brown fox", Variation: TokenVariationWordSuffix, Rank: 20, Weight: 1, Score: 20},
+	}
+	if len(explanation.Results) != len(want) {
+		t.Fatalf("Expected %d explained results, got %+v", len(want), explanation.Results)
+	}
+	for i := range want {
+		if explanation.Results[i] != want[i] {
+			t.Errorf("Result %d got %+v, want %+v", i, explanation.Results[i], want[i])
+		}
+	}
+
+	reasons := make(map[CutReason]int)
+	for _, cut := range explanation.Cuts {
+		reasons[cut.Reason]++
+	}
+	if reasons[CutFiltered] != 1 || reasons[CutDuplicate] != 1 {
+		t.Errorf("Unexpected cuts: %+v", explanation.Cuts)
+	}
+	// The slow fox is never visited as it ranks below the current results
+	if len(explanation.EarlyExits) != 1 || explanation.EarlyExits[0] != (EarlyExit{Token: "brown fox", Skipped: 1}) {
+		t.Errorf("Unexpected early exits: %+v", explanation.EarlyExits)
+	}
+
+	// The bracketed alias sorts first, and is then pushed out by a higher ranked item
+	explanation = index.Explain("the", 1, nil)
+	if len(explanation.Results) != 1 || explanation.Results[0].Item != testItems2[3] {
+		t.Fatalf("Unexpected results: %+v", explanation.Results)
+	}
+	if cut := explanation.Cuts[0]; cut.Reason != CutLimit || cut.Item != testItems2[0] || cut.Token != "the (quick) brown fox" {
+		t.Errorf("Unexpected first cut: %+v", cut)
+	}
+}
@@ -0,0 +1,338 @@
+package lodestar
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSelectionBoost        = 1
+	defaultSelectionMaxBoostMult = 10
+	defaultSelectionCacheSize    = 1024
+)
+
+// ErrSelectionFeedbackDisabled is returned by the selection feedback methods of an index that was
+// created without WithSelectionFeedback.
+var ErrSelectionFeedbackDisabled = errors.New("selection feedback is not enabled")
+
+// SelectionRecord is the learned boost of an item for a normalized query.
+type SelectionRecord struct {
+	Query string `json:"query"`
+	ID    any    `json:"id"`
+
+	// Key identifies the item across round-trips through a SelectionStore, see FeedbackConfig.Key.
+	// Records without a key are matched by the key of their ID.
+	Key string `json:"key"`
+
+	// Boost is the learned boost as of the time the record was updated
+	Boost float64 `json:"boost"`
+
+	// Selections is the number of times the item was selected for the query
+	Selections int `json:"selections"`
+
+	Updated time.Time `json:"updated"`
+}
+
+// SelectionStore persists learned selection boosts. Implementations must be safe for concurrent use.
+type SelectionStore interface {
+	// Load returns the records of a normalized query.
+	Load(query string) ([]SelectionRecord, error)
+
+	// Save stores a record, replacing any previous record for the same query and key.
+	Save(record SelectionRecord) error
+
+	// All returns all records in any order.
+	All() ([]SelectionRecord, error)
+}
+
+// MemorySelectionStore is an in-memory SelectionStore.
+type MemorySelectionStore struct {
+	mu      sync.RWMutex
+	records map[string]map[string]SelectionRecord
+}
+
+// NewMemorySelectionStore creates an empty in-memory SelectionStore.
+func NewMemorySelectionStore() *MemorySelectionStore {
+	return &MemorySelectionStore{records: make(map[string]map[string]SelectionRecord)}
+}
+
+// Load returns the records of a normalized query.
+func (s *MemorySelectionStore) Load(query string) ([]SelectionRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	records := make([]SelectionRecord, 0, len(s.records[query]))
+	for _, record := range s.records[query] {
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// Save stores a record, replacing any previous record for the same query and key.
+func (s *MemorySelectionStore) Save(record SelectionRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, found := s.records[record.Query]; !found {
+		s.records[record.Query] = make(map[string]SelectionRecord)
+	}
+	s.records[record.Query][record.Key] = record
+	return nil
+}
+
+// All returns all records in any order.
+func (s *MemorySelectionStore) All() ([]SelectionRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var records []SelectionRecord
+	for _, queryRecords := range s.records {
+		for _, record := range queryRecords {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// FeedbackConfig configures how selections are learned by RecordSelection.
+type FeedbackConfig struct {
+	// Store persists the learned boosts. Defaults to a MemorySelectionStore.
+	Store SelectionStore
+
+	// SelectionBoost is added to the boost of an item for a query every time it is selected. Defaults to 1.
+	SelectionBoost float64
+
+	// MaxBoost caps the learned boost of an item for a query. Defaults to 10 times the SelectionBoost.
+	MaxBoost float64
+
+	// HalfLife is the time after which learned boosts have halved. Boosts do not decay if HalfLife <= 0.
+	HalfLife time.Duration
+
+	// CacheSize is the number of queries whose records are cached, so that searches do not load them
+	// from the Store every time. Records are reloaded after RecordSelection changes them, but changes
+	// made to the Store by other means are only seen once a query is evicted. Defaults to 1024, and
+	// a negative CacheSize disables caching.
+	CacheSize int
+
+	// Key returns the key that the selections of an item ID are stored and matched by. Keys must be
+	// unique per item and stable across round-trips through the Store, e.g. an int ID saved as JSON is
+	// loaded as a float64. Defaults to formatting the ID with fmt.Sprint, which supports any ID type,
+	// but IDs of different types that format the same, e.g. 42 and "42", share their selections.
+	// Pointer IDs are formatted as addresses, so their selections are only matched within the process.
+	Key func(id any) string
+}
+
+// defaultSelectionKey formats the ID with fmt.Sprint, or as an address if it is a pointer.
+func defaultSelectionKey(id any) string {
+	if reflect.ValueOf(id).Kind() == reflect.Pointer {
+		return fmt.Sprintf("%p", id)
+	}
+	return fmt.Sprint(id)
+}
+
+// selectionFeedback learns boosts from selections, shared by all versions of an index.
+type selectionFeedback struct {
+	config FeedbackConfig
+
+	// Serializes the read-modify-write of records
+	mu sync.Mutex
+
+	// Records of recently searched queries, evicted in no particular order when full.
+	// The generation is incremented by invalidations, so that records loaded before are not cached.
+	cacheMu    sync.RWMutex
+	cache      map[string][]SelectionRecord
+	generation uint64
+
+	// Clock used for decay, overridable for testing
+	now func() time.Time
+}
+
+func newSelectionFeedback(config FeedbackConfig) *selectionFeedback {
+	if config.Store == nil {
+		config.Store = NewMemorySelectionStore()
+	}
+	if config.SelectionBoost <= 0 {
+		config.SelectionBoost = defaultSelectionBoost
+	}
+	if config.MaxBoost <= 0 {
+		config.MaxBoost = config.SelectionBoost * defaultSelectionMaxBoostMult
+	}
+	if config.CacheSize == 0 {
+		config.CacheSize = defaultSelectionCacheSize
+	}
+	if config.Key == nil {
+		config.Key = defaultSelectionKey
+	}
+	f := &selectionFeedback{config: config, now: time.Now}
+	if config.CacheSize > 0 {
+		f.cache = make(map[string][]SelectionRecord, config.CacheSize)
+	}
+	return f
+}
+
+// load returns the records of a normalized query, from the cache if possible.
+func (f *selectionFeedback) load(query string) ([]SelectionRecord, error) {
+	if f.cache == nil {
+		return f.config.Store.Load(query)
+	}
+
+	f.cacheMu.RLock()
+	records, found := f.cache[query]
+	generation := f.generation
+	f.cacheMu.RUnlock()
+	if found {
+		return records, nil
+	}
+
+	records, err := f.config.Store.Load(query)
+	if err != nil {
+		return nil, err
+	}
+
+	f.cacheMu.Lock()
+	defer f.cacheMu.Unlock()
+	if f.generation != generation {
+		return records, nil
+	}
+	if len(f.cache) >= f.config.CacheSize {
+		for cached := range f.cache {
+			delete(f.cache, cached)
+			break
+		}
+	}
+	f.cache[query] = records
+	return records, nil
+}
+
+// invalidate removes the cached records of a normalized query.
+func (f *selectionFeedback) invalidate(query string) {
+	if f.cache == nil {
+		return
+	}
+	f.cacheMu.Lock()
+	defer f.cacheMu.Unlock()
+	delete(f.cache, query)
+	f.generation++
+}
+
+// decay returns the boost of the record decayed up to now.
+func (f *selectionFeedback) decay(record SelectionRecord, now time.Time) float64 {
+	elapsed := now.Sub(record.Updated)
+	if f.config.HalfLife <= 0 || elapsed <= 0 {
+		return record.Boost
+	}
+	return record.Boost * math.Exp2(-float64(elapsed)/float64(f.config.HalfLife))
+}
+
+// recordKey returns the key of the record, or the key of its ID if it has none.
+func (f *selectionFeedback) recordKey(record SelectionRecord) string {
+	if record.Key != "" {
+		return record.Key
+	}
+	return f.config.Key(record.ID)
+}
+
+// boosts returns the decayed boosts of the items selected for a normalized query by their keys,
+// and the largest of them.
+func (f *selectionFeedback) boosts(query string, now time.Time) (map[string]float64, float64, error) {
+	records, err := f.load(query)
+	if err != nil || len(records) == 0 {
+		return nil, 0, err
+	}
+	boosts := make(map[string]float64, len(records))
+	maxBoost := 0.0
+	for _, record := range records {
+		boost := f.decay(record, now)
+		boosts[f.recordKey(record)] = boost
+		maxBoost = max(maxBoost, boost)
+	}
+	return boosts, maxBoost, nil
+}
+
+// RecordSelection records that the item with the given ID was selected from the results of the query,
+// so that it ranks higher in later searches for the same normalized query.
+// Each selection adds to the learned boost of the item for the query, which decays over time and is
+// capped as configured by WithSelectionFeedback.
+func (idx *Index[T]) RecordSelection(query string, id any) error {
+	f := idx.feedback
+	if f == nil {
+		return ErrSelectionFeedbackDisabled
+	}
+	query = idx.tokenizer.NormalizeString(query)
+	if query == "" {
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	records, err := f.config.Store.Load(query)
+	if err != nil {
+		return fmt.Errorf("failed to load selections for query %q: %w", query, err)
+	}
+
+	now := f.now()
+	key := f.config.Key(id)
+	record := SelectionRecord{Query: query, ID: id, Key: key}
+	for _, r := range records {
+		if f.recordKey(r) == key {
+			record = r
+			record.Key = key
+			record.Boost = f.decay(r, now)
+			break
+		}
+	}
+	record.Boost = min(record.Boost+f.config.SelectionBoost, f.config.MaxBoost)
+	record.Selections++
+	record.Updated = now
+
+	// Reload the records of the query even if saving failed, as the store may be partially updated
+	defer f.invalidate(query)
+	if err := f.config.Store.Save(record); err != nil {
+		return fmt.Errorf("failed to save selection for query %q: %w", query, err)
+	}
+	return nil
+}
+
+// Selections returns all learned selection records as stored, sorted by query and then by the
+// current, decayed boost of each record (descending).
+func (idx *Index[T]) Selections() ([]SelectionRecord, error) {
+	f := idx.feedback
+	if f == nil {
+		return nil, ErrSelectionFeedbackDisabled
+	}
+	records, err := f.config.Store.All()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load selections: %w", err)
+	}
+
+	now := f.now()
+	slices.SortStableFunc(records, func(a, b SelectionRecord) int {
+		return cmp.Or(
+			cmp.Compare(a.Query, b.Query),
+			cmp.Compare(f.decay(b, now), f.decay(a, now)),
+		)
+	})
+	return records, nil
+}
+
+// ExportSelections writes all learned selection records to w as JSON lines, for offline inspection.
+// Records are written in the order of Selections.
+func (idx *Index[T]) ExportSelections(w io.Writer) error {
+	records, err := idx.Selections()
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to export selection for query %q: %w", record.Query, err)
+		}
+	}
+	return nil
+}
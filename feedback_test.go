@@ -0,0 +1,206 @@
+package lodestar
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordSelection(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	index, _ := New[*ExampleItem](WithSelectionFeedback(FeedbackConfig{
+		SelectionBoost: 4,
+		MaxBoost:       10,
+		HalfLife:       time.Hour,
+	})).IndexItems(testItems)
+	index.feedback.now = func() time.Time { return clock }
+
+	top := func(query string) string {
+		results, _ := index.PrefixSearch(query, 1, nil)
+		if len(results) == 0 {
+			return ""
+		}
+		return results[0].Text
+	}
+
+	if got := top("ap"); got != "application" {
+		t.Fatalf("Top result before selections = %q, want application", got)
+	}
+
+	// approach (rank 5) needs more than 10 to overtake application (rank 15), so the boost is capped
+	for range 3 {
+		if err := index.RecordSelection("AP", testItems[4].GetID()); err != nil {
+			t.Fatalf("RecordSelection failed: %v", err)
+		}
+	}
+	if got := top("ap"); got != "application" {
+		t.Errorf("Top result with capped boost = %q, want application", got)
+	}
+
+	// apple (rank 10) overtakes application with a boost of 8
+	index.RecordSelection("ap", testItems[0].GetID())
+	index.RecordSelection("ap", testItems[0].GetID())
+	if got := top("ap"); got != "apple" {
+		t.Errorf("Top result after selections = %q, want apple", got)
+	}
+
+	// Boosts only apply to the query they were learned for
+	if got := top("app"); got != "application" {
+		t.Errorf("Top result for another query = %q, want application", got)
+	}
+
+	// After an hour the boost of apple has halved to 4
+	clock = clock.Add(time.Hour)
+	if got := top("ap"); got != "application" {
+		t.Errorf("Top result after decay = %q, want application", got)
+	}
+
+	records, err := index.Selections()
+	if err != nil {
+		t.Fatalf("Selections failed: %v", err)
+	}
+	if len(records) != 2 || records[0].ID != testItems[4].GetID() || records[0].Boost != 10 || records[0].Selections != 3 {
+		t.Errorf("Unexpected selections: %+v", records)
+	}
+
+	var buf bytes.Buffer
+	if err := index.ExportSelections(&buf); err != nil {
+		t.Fatalf("ExportSelections failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 exported lines, got %d", len(lines))
+	}
+	var exported struct {
+		Query      string  `json:"query"`
+		Boost      float64 `json:"boost"`
+		Selections int     `json:"selections"`
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &exported); err != nil {
+		t.Fatalf("Invalid export line %q: %v", lines[1], err)
+	}
+	if exported.Query != "ap" || exported.Boost != 8 || exported.Selections != 2 {
+		t.Errorf("Unexpected exported record: %+v", exported)
+	}
+}
+
+func TestRecordSelectionDisabled(t *testing.T) {
+	index := setupIndexWithItems(testItems)
+	if err := index.RecordSelection("ap", testItems[0].GetID()); !errors.Is(err, ErrSelectionFeedbackDisabled) {
+		t.Errorf("Expected ErrSelectionFeedbackDisabled, got %v", err)
+	}
+}
+
+// countingStore is a SelectionStore counting the loads of the wrapped store, failing them if err is set.
+type countingStore struct {
+	*MemorySelectionStore
+	loads int
+	err   error
+}
+
+func (s *countingStore) Load(query string) ([]SelectionRecord, error) {
+	s.loads++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.MemorySelectionStore.Load(query)
+}
+
+func TestSelectionFeedbackCache(t *testing.T) {
+	store := &countingStore{MemorySelectionStore: NewMemorySelectionStore()}
+	index, _ := New[*ExampleItem](WithSelectionFeedback(FeedbackConfig{Store: store, SelectionBoost: 8})).IndexItems(testItems)
+
+	for range 3 {
+		index.PrefixSearch("ap", 1, nil)
+	}
+	if store.loads != 1 {
+		t.Errorf("Store loaded %d times for repeated searches, want 1", store.loads)
+	}
+
+	// Recording a selection reloads the records of the query
+	if err := index.RecordSelection("ap", testItems[0].GetID()); err != nil {
+		t.Fatalf("RecordSelection failed: %v", err)
+	}
+	if results, _ := index.PrefixSearch("ap", 1, nil); len(results) != 1 || results[0].Text != "apple" {
+		t.Errorf("Top result after selection = %v, want apple", results)
+	}
+
+	// A failing store degrades to no boosts
+	store.err = errors.New("store unavailable")
+	if results, _ := index.PrefixSearch("app", 1, nil); len(results) != 1 || results[0].Text != "application" {
+		t.Errorf("Top result with failing store = %v, want application", results)
+	}
+}
+
+// jsonStore is a SelectionStore persisting records as JSON, so that int IDs are loaded as float64.
+type jsonStore struct {
+	records map[string][][]byte
+}
+
+func (s *jsonStore) Load(query string) ([]SelectionRecord, error) {
+	var records []SelectionRecord
+	for _, data := range s.records[query] {
+		var record SelectionRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (s *jsonStore) Save(record SelectionRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	records, _ := s.Load(record.Query)
+	s.records[record.Query] = nil
+	for _, r := range records {
+		if r.Key != record.Key {
+			other, _ := json.Marshal(r)
+			s.records[record.Query] = append(s.records[record.Query], other)
+		}
+	}
+	s.records[record.Query] = append(s.records[record.Query], data)
+	return nil
+}
+
+func (s *jsonStore) All() ([]SelectionRecord, error) { return nil, nil }
+
+func TestSelectionFeedbackKeys(t *testing.T) {
+	items := []*orderedItem{
+		{ExampleItem: ExampleItem{Text: "apple", Rank: 10}, ID: 1},
+		{ExampleItem: ExampleItem{Text: "application", Rank: 15}, ID: 2},
+	}
+	store := &jsonStore{records: make(map[string][][]byte)}
+	index, _ := New[*orderedItem](WithSelectionFeedback(FeedbackConfig{Store: store, SelectionBoost: 4})).IndexItems(items)
+
+	// The int ID of apple is loaded as a float64, and still matched by its key
+	for range 2 {
+		if err := index.RecordSelection("ap", 1); err != nil {
+			t.Fatalf("RecordSelection failed: %v", err)
+		}
+	}
+	if records, _ := store.Load("ap"); len(records) != 1 || records[0].Selections != 2 || records[0].Key != "1" {
+		t.Errorf("Unexpected stored records: %+v", records)
+	}
+	if results, _ := index.PrefixSearch("ap", 1, nil); len(results) != 1 || results[0].ID != 1 {
+		t.Errorf("Top result after selections = %v, want apple", results)
+	}
+
+	// Records without keys are matched by their IDs
+	store.records["app"] = [][]byte{[]byte(`{"query":"app","id":1,"boost":8,"selections":2}`)}
+	if results, _ := index.PrefixSearch("app", 1, nil); len(results) != 1 || results[0].ID != 1 {
+		t.Errorf("Top result for record without key = %v, want apple", results)
+	}
+
+	// IDs that are not comparable are supported by the default key
+	memoryIndex, _ := New[*ExampleItem](WithSelectionFeedback(FeedbackConfig{})).IndexItems(testItems)
+	if err := memoryIndex.RecordSelection("ap", []string{"apple"}); err != nil {
+		t.Errorf("RecordSelection with a slice ID failed: %v", err)
+	}
+}
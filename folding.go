@@ -0,0 +1,121 @@
+package lodestar
+
+import (
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// defaultFoldedPenalty is the penalty of matches of the folded form of a query created by NewFoldingTokenizer.
+const defaultFoldedPenalty = 0.1
+
+// FoldingTokenizer wraps a Tokenizer with Unicode normalization. Values and queries are normalized to
+// NFKC and lowercased, and a folded form, with full case folding (e.g. "ß" to "ss") and accents stripped
+// (e.g. "café" to "cafe"), is indexed alongside the accented form.
+//
+// A query is normalized to its accented form, and its folded form is searched as well, so that
+// "cafe" finds "café" while "café" ranks exact-accent matches above matches of "cafe".
+type FoldingTokenizer struct {
+	tokenizerWrapper
+
+	// Language enables language specific lowercasing, e.g. language.Turkish lowercases "I" to "ı"
+	// and "İ" to "i". The zero value lowercases independently of the language.
+	Language language.Tag
+
+	// FoldedPenalty reduces the scores of matches of the folded form of a query that differs from the
+	// query, from 0 for no penalty to 1.
+	FoldedPenalty float64
+}
+
+// NewFoldingTokenizer creates a FoldingTokenizer wrapping the tokenizer, with a folded penalty of 0.1.
+// If tokenizer is nil, the DefaultTokenizer is used.
+func NewFoldingTokenizer(tokenizer Tokenizer, lang language.Tag) *FoldingTokenizer {
+	if tokenizer == nil {
+		tokenizer = &DefaultTokenizer{}
+	}
+	return &FoldingTokenizer{
+		tokenizerWrapper: tokenizerWrapper{Tokenizer: tokenizer},
+		Language:         lang,
+		FoldedPenalty:    defaultFoldedPenalty,
+	}
+}
+
+func (t *FoldingTokenizer) Tokenize(item IndexableItem) []string {
+	var allTokens []string
+	for _, value := range item.GetValuesForIndexing() {
+		accented := t.accented(value)
+		allTokens = mergeUniqueTokens(allTokens, t.Tokenizer.Tokenize(&valueItem{value: accented}))
+		if folded := t.fold(accented); folded != accented {
+			allTokens = mergeUniqueTokens(allTokens, t.Tokenizer.Tokenize(&valueItem{value: folded}))
+		}
+	}
+	return allTokens
+}
+
+// NormalizeString normalizes the value to NFKC, lowercases it and normalizes it with the wrapped tokenizer.
+func (t *FoldingTokenizer) NormalizeString(value string) string {
+	return t.Tokenizer.NormalizeString(t.accented(value))
+}
+
+// TokenizeQuery returns the normalized forms of the accented query from the wrapped tokenizer.
+func (t *FoldingTokenizer) TokenizeQuery(query string) []string {
+	return t.tokenizerWrapper.TokenizeQuery(t.accented(query))
+}
+
+// queryForms returns the forms of the wrapped tokenizer, and the folded form of the normalized query,
+// if it differs.
+func (t *FoldingTokenizer) queryForms(normalized string) []queryForm {
+	forms := t.tokenizerWrapper.queryForms(normalized)
+	folded := t.fold(normalized)
+	if folded == normalized {
+		return forms
+	}
+	return append(forms, queryForm{prefix: folded, factor: 1 - min(max(t.FoldedPenalty, 0), 1)})
+}
+
+// accented returns the NFKC normalized and lowercased value, keeping its accents.
+func (t *FoldingTokenizer) accented(value string) string {
+	return cases.Lower(t.Language).String(norm.NFKC.String(value))
+}
+
+// fold applies full case folding to the value and strips its accents.
+func (t *FoldingTokenizer) fold(value string) string {
+	stripAccents := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(stripAccents, cases.Fold().String(value))
+	if err != nil {
+		return value
+	}
+	return folded
+}
+
+// Analyze returns the tokens of the accented value, followed by the tokens of the folded value.
+func (t *FoldingTokenizer) Analyze(value string) []TokenInfo {
+	accented := t.accented(value)
+	tokens := t.tokenizerWrapper.Analyze(accented)
+	if folded := t.fold(accented); folded != accented {
+		for _, token := range analyzeValue(t.Tokenizer, folded) {
+			token.Variation = TokenVariationFolded
+			tokens = append(tokens, token)
+		}
+	}
+	return uniqueTokenInfos(tokens)
+}
+
+// tokenVariation returns the rule of the wrapped tokenizer that produced the token from the accented
+// value, or TokenVariationFolded if it was produced from the folded value.
+func (t *FoldingTokenizer) tokenVariation(value string, token string) TokenVariation {
+	accented := t.accented(value)
+	if variation := t.tokenizerWrapper.tokenVariation(accented, token); variation != TokenVariationUnknown {
+		return variation
+	}
+	for _, foldedToken := range t.Tokenizer.Tokenize(&valueItem{value: t.fold(accented)}) {
+		if foldedToken == token {
+			return TokenVariationFolded
+		}
+	}
+	return TokenVariationUnknown
+}
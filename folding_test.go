@@ -0,0 +1,60 @@
+package lodestar
+
+import (
+	"slices"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestFoldingTokenizer(t *testing.T) {
+	tr := NewFoldingTokenizer(nil, language.Und)
+
+	got := tr.Tokenize(&valueItem{value: "Café Straße"})
+	want := []string{"cafe strasse", "café straße", "strasse", "straße"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Tokenize() = %v, want %v", got, want)
+	}
+
+	// NFC and NFD inputs, and compatibility characters, normalize identically
+	for _, value := range []string{"Café", "Café", "ＣＡＦÉ"} {
+		if got := tr.NormalizeString(value); got != "café" {
+			t.Errorf("NormalizeString(%q) = %q, want café", value, got)
+		}
+	}
+
+	turkish := NewFoldingTokenizer(nil, language.Turkish)
+	if got := turkish.NormalizeString("İSTANBUL"); got != "istanbul" {
+		t.Errorf("Turkish NormalizeString() = %q, want istanbul", got)
+	}
+	if got := turkish.NormalizeString("KIR"); got != "kır" {
+		t.Errorf("Turkish NormalizeString() = %q, want kır", got)
+	}
+
+	if got := tr.tokenVariation("Café", "cafe"); got != TokenVariationFolded {
+		t.Errorf("tokenVariation() = %v, want %v", got, TokenVariationFolded)
+	}
+}
+
+func TestPrefixSearchFolding(t *testing.T) {
+	items := []*ExampleItem{
+		{Text: "Cafe Nero", Rank: 5},
+		{Text: "Café de Flore", Rank: 5},
+		{Text: "Crème brûlée", Rank: 1},
+	}
+	index, _ := New[*ExampleItem](WithTokenizer(NewFoldingTokenizer(nil, language.Und))).IndexItems(items)
+
+	for query, want := range map[string][]string{
+		"cafe":   {"Cafe Nero", "Café de Flore"},
+		"café":   {"Café de Flore", "Cafe Nero"},
+		"CAFÉ D": {"Café de Flore"},
+		"creme":  {"Crème brûlée"},
+		"brûl":   {"Crème brûlée"},
+	} {
+		results, _ := index.PrefixSearch(query, 0, nil)
+		got := resultTexts(results)
+		if !slices.Equal(got, want) {
+			t.Errorf("PrefixSearch(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
@@ -2,6 +2,9 @@ module github.com/regalias/lodestar
 
 go 1.24.5
 
-require github.com/hashicorp/go-immutable-radix/v2 v2.1.0
+require (
+	github.com/hashicorp/go-immutable-radix/v2 v2.1.0
+	golang.org/x/text v0.34.0
+)
 
 require github.com/hashicorp/golang-lru/v2 v2.0.0 // indirect
@@ -0,0 +1,143 @@
+package lodestar
+
+import (
+	"container/heap"
+	"slices"
+)
+
+// Groupable is an optional interface for items that belong to a group, e.g. a category or the
+// product that several variants belong to. It is used by WithGroupBy when no key function is given.
+type Groupable interface {
+	// GroupKey returns any hashable value identifying the group of the item.
+	GroupKey() any
+}
+
+// groupableKey returns the group key of Groupable items, or the ID of the item otherwise so that
+// items without a group are never collapsed.
+func groupableKey[T IndexableItem](item T) any {
+	if groupable, ok := any(item).(Groupable); ok {
+		return groupable.GroupKey()
+	}
+	return item.GetID()
+}
+
+// groupCollector aggregates the top results subject to a maximum number of results per group.
+// Results evicted from their group are lazily removed from the heap.
+type groupCollector[T IndexableItem] struct {
+	limit      int
+	groupLimit int
+	groupKey   func(item T) any
+	tieBreaker TieBreaker
+	trace      *searchTrace[T]
+
+	// Reason reported for results left out by the group limit
+	groupCut CutReason
+
+	minHeap resultHeap[T]
+	groups  map[any][]result[T]
+
+	// Score of the live heap entry of each item, entries of removed items are stale
+	live map[any]float64
+}
+
+func newGroupCollector[T IndexableItem](limit int, groupKey func(item T) any, groupLimit int, groupCut CutReason, tieBreaker TieBreaker, trace *searchTrace[T]) *groupCollector[T] {
+	return &groupCollector[T]{
+		limit:      limit,
+		groupLimit: groupLimit,
+		groupKey:   groupKey,
+		tieBreaker: tieBreaker,
+		trace:      trace,
+		groupCut:   groupCut,
+		minHeap:    resultHeap[T]{tieBreaker: tieBreaker},
+		groups:     make(map[any][]result[T]),
+		live:       make(map[any]float64),
+	}
+}
+
+// offer adds the candidate to the results if it ranks within the top results and within the top of its group.
+// It returns true if neither the candidate nor any lower ranked candidate can be added.
+func (c *groupCollector[T]) offer(candidate result[T]) (stop bool) {
+	key := c.groupKey(candidate.Value)
+
+	// A weighted item may be offered again with a higher score, replacing its previous entry
+	if _, found := c.live[candidate.Value.GetID()]; found {
+		c.remove(key, candidate)
+	}
+	c.pruneRemoved()
+
+	if len(c.live) >= c.limit && compareResults(c.tieBreaker, candidate, c.minHeap.results[0]) >= 0 {
+		// Current item is not better than the worst result, so neither are the rest of the posting list
+		return true
+	}
+
+	members := c.groups[key]
+	if len(members) >= c.groupLimit {
+		worst := slices.MaxFunc(members, func(a, b result[T]) int {
+			return compareResults(c.tieBreaker, a, b)
+		})
+		if compareResults(c.tieBreaker, candidate, worst) >= 0 {
+			// The group is full of better items, but other groups may still accept later items
+			c.trace.cut(candidate.Value, candidate.Token, c.groupCut)
+			return false
+		}
+		// Replace the worst member of the group
+		c.remove(key, worst)
+		c.trace.evict(worst.Value, c.groupCut)
+	} else if len(c.live) >= c.limit {
+		// Replace the worst result overall
+		evicted := c.minHeap.results[0]
+		c.remove(c.groupKey(evicted.Value), evicted)
+		c.pruneRemoved()
+		c.trace.evict(evicted.Value, CutLimit)
+	}
+
+	heap.Push(&c.minHeap, candidate)
+	c.live[candidate.Value.GetID()] = candidate.Score
+	c.groups[key] = append(c.groups[key], candidate)
+	c.trace.accept(candidate)
+	return false
+}
+
+// remove lazily removes a result from the heap, and removes it from its group.
+func (c *groupCollector[T]) remove(key any, r result[T]) {
+	id := r.Value.GetID()
+	delete(c.live, id)
+	c.groups[key] = slices.DeleteFunc(c.groups[key], func(member result[T]) bool {
+		return member.Value.GetID() == id
+	})
+	if len(c.groups[key]) == 0 {
+		delete(c.groups, key)
+	}
+}
+
+// pruneRemoved pops removed results from the top of the heap, so that the top is the worst live result.
+func (c *groupCollector[T]) pruneRemoved() {
+	for c.minHeap.Len() > 0 {
+		top := c.minHeap.results[0]
+		if score, found := c.live[top.Value.GetID()]; found && score == top.Score {
+			return
+		}
+		heap.Pop(&c.minHeap)
+	}
+}
+
+// results returns the collected items, with groups ordered by their best item and the items
+// of each group kept together in rank order.
+func (c *groupCollector[T]) results() []result[T] {
+	keys := make([]any, 0, len(c.groups))
+	for key, members := range c.groups {
+		slices.SortFunc(members, func(a, b result[T]) int {
+			return compareResults(c.tieBreaker, a, b)
+		})
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b any) int {
+		return compareResults(c.tieBreaker, c.groups[a][0], c.groups[b][0])
+	})
+
+	results := make([]result[T], 0, len(c.live))
+	for _, key := range keys {
+		results = append(results, c.groups[key]...)
+	}
+	return results
+}
@@ -0,0 +1,60 @@
+package lodestar
+
+import (
+	"slices"
+	"testing"
+)
+
+type categoryItem struct {
+	ExampleItem
+	Category string
+}
+
+func (c *categoryItem) GroupKey() any {
+	return c.Category
+}
+
+func TestPrefixSearchGroupBy(t *testing.T) {
+	items := []*categoryItem{
+		{ExampleItem: ExampleItem{Text: "apple iphone", Rank: 50}, Category: "phones"},
+		{ExampleItem: ExampleItem{Text: "apple ipad", Rank: 45}, Category: "tablets"},
+		{ExampleItem: ExampleItem{Text: "apple iphone mini", Rank: 40}, Category: "phones"},
+		{ExampleItem: ExampleItem{Text: "apple iphone pro", Rank: 48}, Category: "phones"},
+		{ExampleItem: ExampleItem{Text: "apple watch", Rank: 30}, Category: "watches"},
+		{ExampleItem: ExampleItem{Text: "apple ipad mini", Rank: 20}, Category: "tablets"},
+	}
+	index, _ := New[*categoryItem]().IndexItems(items)
+
+	// Best 2 per group via the Groupable interface, groups ordered by their best member
+	results, _ := index.PrefixSearch("apple", 0, nil, WithGroupBy[*categoryItem](nil, 2))
+	want := []string{"apple iphone", "apple iphone pro", "apple ipad", "apple ipad mini", "apple watch"}
+	if got := resultTexts(results); !slices.Equal(got, want) {
+		t.Errorf("Grouped search got %v, want %v", got, want)
+	}
+
+	// The limit caps the total number of results, filled with the best items per group
+	results, _ = index.PrefixSearch("apple", 3, nil, WithGroupBy[*categoryItem](nil, 1))
+	want = []string{"apple iphone", "apple ipad", "apple watch"}
+	if got := resultTexts(results); !slices.Equal(got, want) {
+		t.Errorf("Grouped search with limit got %v, want %v", got, want)
+	}
+
+	// Custom key function collapsing everything into one group
+	results, _ = index.PrefixSearch("apple", 0, nil, WithGroupBy(func(*categoryItem) any { return "all" }, 2))
+	want = []string{"apple iphone", "apple iphone pro"}
+	if got := resultTexts(results); !slices.Equal(got, want) {
+		t.Errorf("Grouped search with key function got %v, want %v", got, want)
+	}
+
+	// Items replaced within their group are reported as cut by the group limit
+	explanation := index.Explain("apple", 0, nil, WithGroupBy[*categoryItem](nil, 1))
+	cut := 0
+	for _, c := range explanation.Cuts {
+		if c.Reason == CutGroupLimit {
+			cut++
+		}
+	}
+	if len(explanation.Results) != 3 || cut != 3 {
+		t.Errorf("Expected 3 results and 3 group limit cuts, got %+v", explanation)
+	}
+}
@@ -3,6 +3,8 @@ package lodestar
 import (
 	"fmt"
 	"slices"
+
+	iradix "github.com/hashicorp/go-immutable-radix/v2"
 )
 
 // IndexItems indexes a batch of items, adding them to the immutable radix tree by their tokenized indexes.
@@ -15,48 +17,104 @@ func (idx *Index[T]) IndexItems(items []T) (*Index[T], error) {
 
 	invertedIndex := make(invertedIndex[T], 0)
 	// Build the inverted index
-	for _, item := range items {
-		if err := idx.addToInvertedIndex(invertedIndex, item); err != nil {
+	weighted := idx.weighted
+	for i, item := range items {
+		itemWeighted, err := idx.addToInvertedIndex(invertedIndex, item, idx.nextSeq+uint64(i))
+		if err != nil {
 			return nil, fmt.Errorf("failed to index item %v: %w", item, err)
 		}
+		weighted = weighted || itemWeighted
 	}
 
 	// Make a new index with the updated immutable radix tree
 	newIndex := &Index[T]{
-		tokenizer: idx.tokenizer,
+		tokenizer:        idx.tokenizer,
+		tieBreaker:       idx.tieBreaker,
+		nextSeq:          idx.nextSeq + uint64(len(items)),
+		weighted:         weighted,
+		symSpell:         idx.symSpell,
+		buffers:          idx.buffers,
+		batchConcurrency: idx.batchConcurrency,
+		overlay:          idx.overlay,
+		feedback:         idx.feedback,
+		synonyms:         idx.synonyms,
+		synonymPenalty:   idx.synonymPenalty,
+	}
+
+	var deletesTx *iradix.Txn[[]string]
+	if idx.deletes != nil {
+		deletesTx = idx.deletes.Txn()
 	}
 
 	// Index each item in the immutable radix tree
 	tx := idx.index.Txn()
-	for token, tokenItems := range invertedIndex {
-		// Sort the inverted index by descending rank first
-		if len(tokenItems) > 1 {
-			slices.SortFunc(tokenItems, func(a, b T) int {
-				return b.GetRank() - a.GetRank()
+	for token, postings := range invertedIndex {
+		// Only new tokens need to be added to the deletion dictionary, namespaced tokens are never suggested
+		if deletesTx != nil && !isNamespacedToken(token) {
+			if _, exists := tx.Get([]byte(token)); !exists {
+				addToDeletionDictionary(deletesTx, token, idx.symSpell)
+			}
+		}
+
+		// Sort the inverted index by descending score first, then by the tie breaker
+		if len(postings) > 1 {
+			slices.SortFunc(postings, func(a, b posting[T]) int {
+				return comparePostings(idx.tieBreaker, a, b)
 			})
-			invertedIndex[token] = tokenItems
+			invertedIndex[token] = postings
 		}
 		// Add the token to the immutable radix tree
-		tx.Insert([]byte(token), tokenItems)
+		tx.Insert([]byte(token), postings)
 	}
 	newIndex.index = tx.Commit()
+	if deletesTx != nil {
+		newIndex.deletes = deletesTx.Commit()
+	}
 
 	return newIndex, nil
 }
 
 // addToInvertedIndex indexes a single item by tokenizing it and adding the tokens to the inverted index.
-func (idx *Index[T]) addToInvertedIndex(invertedIndex invertedIndex[T], item T) error {
-	tokens := idx.tokenizer.Tokenize(item)
-	if len(tokens) == 0 {
-		return fmt.Errorf("no tokens generated for item: %v", item)
+// It returns true if any of the tokens were weighted differently from the default weight of 1.
+func (idx *Index[T]) addToInvertedIndex(invertedIndex invertedIndex[T], item T, seq uint64) (bool, error) {
+	tokenWeights := idx.tokenizeWeighted(item)
+	if len(tokenWeights) == 0 {
+		return false, fmt.Errorf("no tokens generated for item: %v", item)
 	}
 
-	for _, token := range tokens {
+	weighted := false
+	for token, weight := range tokenWeights {
 		if _, found := invertedIndex[token]; !found {
-			invertedIndex[token] = make([]T, 0)
+			invertedIndex[token] = make([]posting[T], 0)
 		}
-		invertedIndex[token] = append(invertedIndex[token], item)
+		invertedIndex[token] = append(invertedIndex[token], posting[T]{item: item, weight: weight, seq: seq})
+		weighted = weighted || weight != 1
 		// TODO: ensure item is unique?
 	}
-	return nil
+	return weighted, nil
+}
+
+// tokenizeWeighted tokenizes an item into its unique tokens and their weights.
+// The values of items implementing WeightedIndexableItem are tokenized one at a time, keeping the
+// highest weight of each token, otherwise all tokens have a weight of 1.
+func (idx *Index[T]) tokenizeWeighted(item T) map[string]float64 {
+	weightedItem, ok := any(item).(WeightedIndexableItem)
+	if !ok {
+		tokens := idx.tokenizer.Tokenize(item)
+		tokenWeights := make(map[string]float64, len(tokens))
+		for _, token := range tokens {
+			tokenWeights[token] = 1
+		}
+		return tokenWeights
+	}
+
+	tokenWeights := make(map[string]float64)
+	for _, value := range weightedItem.GetWeightedValuesForIndexing() {
+		for _, token := range idx.tokenizer.Tokenize(&weightedValueItem{IndexableItem: weightedItem, value: value.Text}) {
+			if weight, found := tokenWeights[token]; !found || value.Weight > weight {
+				tokenWeights[token] = value.Weight
+			}
+		}
+	}
+	return tokenWeights
 }
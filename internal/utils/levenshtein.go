@@ -0,0 +1,46 @@
+package utils
+
+// LevenshteinDistance computes the edit distance between two rune slices, counting insertions,
+// deletions and substitutions. If the distance is known to exceed maxDistance, the computation
+// stops early and maxDistance+1 is returned. A negative maxDistance disables the bound.
+func LevenshteinDistance(a, b []rune, maxDistance int) int {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	if maxDistance >= 0 && len(a)-len(b) > maxDistance {
+		return maxDistance + 1
+	}
+	if len(b) == 0 {
+		return len(a)
+	}
+
+	// Two rolling rows of the dynamic programming matrix
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+		// Every path through the remaining rows costs at least the row minimum
+		if maxDistance >= 0 && rowMin > maxDistance {
+			return maxDistance + 1
+		}
+		prev, curr = curr, prev
+	}
+
+	if maxDistance >= 0 && prev[len(b)] > maxDistance {
+		return maxDistance + 1
+	}
+	return prev[len(b)]
+}
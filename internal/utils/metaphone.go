@@ -0,0 +1,571 @@
+package utils
+
+import (
+	"strings"
+)
+
+// doubleMetaphoneMaxLength is the maximum length of Double Metaphone codes.
+const doubleMetaphoneMaxLength = 4
+
+// DoubleMetaphone returns the primary and alternate Double Metaphone codes of a word, following
+// Lawrence Philips' original algorithm. Characters other than letters are ignored, and both codes are
+// empty if the word has no letters.
+func DoubleMetaphone(word string) (primary, alternate string) {
+	m := &metaphone{value: []rune(strings.ToUpper(strings.TrimSpace(word)))}
+	if len(m.value) == 0 {
+		return "", ""
+	}
+	m.slavoGermanic = m.isSlavoGermanic()
+
+	index := 0
+	if m.contains(0, 2, "GN", "KN", "PN", "WR", "PS") {
+		// Silent start
+		index = 1
+	}
+	for !m.complete() && index < len(m.value) {
+		switch m.at(index) {
+		case 'A', 'E', 'I', 'O', 'U', 'Y':
+			if index == 0 {
+				m.add("A")
+			}
+			index++
+		case 'B':
+			m.add("P")
+			index = m.skip(index, "B")
+		case 'Ç':
+			m.add("S")
+			index++
+		case 'C':
+			index = m.handleC(index)
+		case 'D':
+			index = m.handleD(index)
+		case 'F':
+			m.add("F")
+			index = m.skip(index, "F")
+		case 'G':
+			index = m.handleG(index)
+		case 'H':
+			index = m.handleH(index)
+		case 'J':
+			index = m.handleJ(index)
+		case 'K':
+			m.add("K")
+			index = m.skip(index, "K")
+		case 'L':
+			index = m.handleL(index)
+		case 'M':
+			m.add("M")
+			if m.at(index+1) == 'M' || (m.contains(index-1, 3, "UMB") &&
+				(index+1 == len(m.value)-1 || m.contains(index+2, 2, "ER"))) {
+				index += 2
+			} else {
+				index++
+			}
+		case 'N':
+			m.add("N")
+			index = m.skip(index, "N")
+		case 'Ñ':
+			m.add("N")
+			index++
+		case 'P':
+			if m.at(index+1) == 'H' {
+				m.add("F")
+				index += 2
+			} else {
+				m.add("P")
+				index = m.skip(index, "P", "B")
+			}
+		case 'Q':
+			m.add("K")
+			index = m.skip(index, "Q")
+		case 'R':
+			if index == len(m.value)-1 && !m.slavoGermanic && m.contains(index-2, 2, "IE") &&
+				!m.contains(index-4, 2, "ME", "MA") {
+				m.addAlternate("R")
+			} else {
+				m.add("R")
+			}
+			index = m.skip(index, "R")
+		case 'S':
+			index = m.handleS(index)
+		case 'T':
+			index = m.handleT(index)
+		case 'V':
+			m.add("F")
+			index = m.skip(index, "V")
+		case 'W':
+			index = m.handleW(index)
+		case 'X':
+			index = m.handleX(index)
+		case 'Z':
+			index = m.handleZ(index)
+		default:
+			index++
+		}
+	}
+	return m.primary.String(), m.alternate.String()
+}
+
+// metaphone holds the state of encoding a word with DoubleMetaphone.
+type metaphone struct {
+	value              []rune
+	slavoGermanic      bool
+	primary, alternate strings.Builder
+}
+
+// at returns the character at the index, or 0 if it is out of range.
+func (m *metaphone) at(index int) rune {
+	if index < 0 || index >= len(m.value) {
+		return 0
+	}
+	return m.value[index]
+}
+
+// contains returns true if the characters from start to start+length equal one of the candidates.
+func (m *metaphone) contains(start, length int, candidates ...string) bool {
+	if start < 0 || start+length > len(m.value) {
+		return false
+	}
+	target := string(m.value[start : start+length])
+	for _, candidate := range candidates {
+		if target == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// skip returns the index after the character, skipping the next character if it is one of the candidates.
+func (m *metaphone) skip(index int, candidates ...string) int {
+	if m.contains(index+1, 1, candidates...) {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) isVowel(r rune) bool {
+	return strings.ContainsRune("AEIOUY", r)
+}
+
+func (m *metaphone) isSlavoGermanic() bool {
+	value := string(m.value)
+	return strings.ContainsAny(value, "WK") || strings.Contains(value, "CZ")
+}
+
+func (m *metaphone) complete() bool {
+	return m.primary.Len() >= doubleMetaphoneMaxLength && m.alternate.Len() >= doubleMetaphoneMaxLength
+}
+
+// add appends the code to the primary and alternate codes.
+func (m *metaphone) add(code string) {
+	m.addPrimary(code)
+	m.addAlternate(code)
+}
+
+// addBoth appends different codes to the primary and alternate codes.
+func (m *metaphone) addBoth(primary, alternate string) {
+	m.addPrimary(primary)
+	m.addAlternate(alternate)
+}
+
+func (m *metaphone) addPrimary(code string) {
+	appendCode(&m.primary, code)
+}
+
+func (m *metaphone) addAlternate(code string) {
+	appendCode(&m.alternate, code)
+}
+
+func appendCode(b *strings.Builder, code string) {
+	if remaining := doubleMetaphoneMaxLength - b.Len(); remaining > 0 {
+		b.WriteString(code[:min(len(code), remaining)])
+	}
+}
+
+func (m *metaphone) handleC(index int) int {
+	switch {
+	case m.conditionC0(index):
+		m.add("K")
+		return index + 2
+	case index == 0 && m.contains(index, 6, "CAESAR"):
+		m.add("S")
+		return index + 2
+	case m.contains(index, 2, "CH"):
+		return m.handleCH(index)
+	case m.contains(index, 2, "CZ") && !m.contains(index-2, 4, "WICZ"):
+		// "Czerny"
+		m.addBoth("S", "X")
+		return index + 2
+	case m.contains(index+1, 3, "CIA"):
+		// "focaccia"
+		m.add("X")
+		return index + 3
+	case m.contains(index, 2, "CC") && !(index == 1 && m.at(0) == 'M'):
+		// Double "cc" but not "McClelland"
+		return m.handleCC(index)
+	case m.contains(index, 2, "CK", "CG", "CQ"):
+		m.add("K")
+		return index + 2
+	case m.contains(index, 2, "CI", "CE", "CY"):
+		// Italian vs. English
+		if m.contains(index, 3, "CIO", "CIE", "CIA") {
+			m.addBoth("S", "X")
+		} else {
+			m.add("S")
+		}
+		return index + 2
+	}
+
+	m.add("K")
+	switch {
+	case m.contains(index+1, 2, " C", " Q", " G"):
+		// "Mac Caffrey", "Mac Gregor"
+		return index + 3
+	case m.contains(index+1, 1, "C", "K", "Q") && !m.contains(index+1, 2, "CE", "CI"):
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleCC(index int) int {
+	if m.contains(index+2, 1, "I", "E", "H") && !m.contains(index+2, 2, "HU") {
+		// "bellocchio" but not "bacchus"
+		if (index == 1 && m.at(index-1) == 'A') || m.contains(index-1, 5, "UCCEE", "UCCES") {
+			// "accident", "accede", "succeed"
+			m.add("KS")
+		} else {
+			// "bacci", "bertucci", other Italian
+			m.add("X")
+		}
+		return index + 3
+	}
+	// Pierce's rule
+	m.add("K")
+	return index + 2
+}
+
+func (m *metaphone) handleCH(index int) int {
+	switch {
+	case index > 0 && m.contains(index, 4, "CHAE"):
+		// "Michael"
+		m.addBoth("K", "X")
+	case m.conditionCH0(index), m.conditionCH1(index):
+		// Greek roots, e.g. "chemistry", or Germanic 'ch' for 'kh' sound
+		m.add("K")
+	case index > 0:
+		if m.contains(0, 2, "MC") {
+			m.add("K")
+		} else {
+			m.addBoth("X", "K")
+		}
+	default:
+		m.add("X")
+	}
+	return index + 2
+}
+
+func (m *metaphone) handleD(index int) int {
+	switch {
+	case m.contains(index, 2, "DG"):
+		if m.contains(index+2, 1, "I", "E", "Y") {
+			// "edge"
+			m.add("J")
+			return index + 3
+		}
+		// "Edgar"
+		m.add("TK")
+		return index + 2
+	case m.contains(index, 2, "DT", "DD"):
+		m.add("T")
+		return index + 2
+	}
+	m.add("T")
+	return index + 1
+}
+
+func (m *metaphone) handleG(index int) int {
+	switch {
+	case m.at(index+1) == 'H':
+		return m.handleGH(index)
+	case m.at(index+1) == 'N':
+		switch {
+		case index == 1 && m.isVowel(m.at(0)) && !m.slavoGermanic:
+			m.addBoth("KN", "N")
+		case !m.contains(index+2, 2, "EY") && m.at(index+1) != 'Y' && !m.slavoGermanic:
+			m.addBoth("N", "KN")
+		default:
+			m.add("KN")
+		}
+		return index + 2
+	case m.contains(index+1, 2, "LI") && !m.slavoGermanic:
+		m.addBoth("KL", "L")
+		return index + 2
+	case index == 0 && (m.at(index+1) == 'Y' ||
+		m.contains(index+1, 2, "ES", "EP", "EB", "EL", "EY", "IB", "IL", "IN", "IE", "EI", "ER")):
+		// -ges-, -gep-, -gel-, -gie- at the beginning
+		m.addBoth("K", "J")
+		return index + 2
+	case (m.contains(index+1, 2, "ER") || m.at(index+1) == 'Y') &&
+		!m.contains(0, 6, "DANGER", "RANGER", "MANGER") &&
+		!m.contains(index-1, 1, "E", "I") &&
+		!m.contains(index-1, 3, "RGY", "OGY"):
+		// -ger-, -gy-
+		m.addBoth("K", "J")
+		return index + 2
+	case m.contains(index+1, 1, "E", "I", "Y") || m.contains(index-1, 4, "AGGI", "OGGI"):
+		// Italian "biaggi"
+		switch {
+		case m.contains(0, 4, "VAN ", "VON ") || m.contains(0, 3, "SCH") || m.contains(index+1, 2, "ET"):
+			// Obvious Germanic
+			m.add("K")
+		case m.contains(index+1, 3, "IER"):
+			m.add("J")
+		default:
+			m.addBoth("J", "K")
+		}
+		return index + 2
+	case m.at(index+1) == 'G':
+		m.add("K")
+		return index + 2
+	}
+	m.add("K")
+	return index + 1
+}
+
+func (m *metaphone) handleGH(index int) int {
+	switch {
+	case index > 0 && !m.isVowel(m.at(index-1)):
+		m.add("K")
+	case index == 0:
+		if m.at(index+2) == 'I' {
+			m.add("J")
+		} else {
+			m.add("K")
+		}
+	case (index > 1 && m.contains(index-2, 1, "B", "H", "D")) ||
+		(index > 2 && m.contains(index-3, 1, "B", "H", "D")) ||
+		(index > 3 && m.contains(index-4, 1, "B", "H")):
+		// Parker's rule, e.g. "hugh"
+	case index > 2 && m.at(index-1) == 'U' && m.contains(index-3, 1, "C", "G", "L", "R", "T"):
+		// "laugh", "McLaughlin", "cough", "gough", "rough", "tough"
+		m.add("F")
+	case m.at(index-1) != 'I':
+		m.add("K")
+	}
+	return index + 2
+}
+
+func (m *metaphone) handleH(index int) int {
+	// Only kept if first and before a vowel, or between two vowels
+	if (index == 0 || m.isVowel(m.at(index-1))) && m.isVowel(m.at(index+1)) {
+		m.add("H")
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleJ(index int) int {
+	if m.contains(index, 4, "JOSE") || m.contains(0, 4, "SAN ") {
+		// Obvious Spanish, "Jose", "San Jacinto"
+		if (index == 0 && m.at(index+4) == ' ') || len(m.value) == 4 || m.contains(0, 4, "SAN ") {
+			m.add("H")
+		} else {
+			m.addBoth("J", "H")
+		}
+		return index + 1
+	}
+
+	switch {
+	case index == 0:
+		m.addBoth("J", "A")
+	case m.isVowel(m.at(index-1)) && !m.slavoGermanic && (m.at(index+1) == 'A' || m.at(index+1) == 'O'):
+		m.addBoth("J", "H")
+	case index == len(m.value)-1:
+		m.addPrimary("J")
+	case !m.contains(index+1, 1, "L", "T", "K", "S", "N", "M", "B", "Z") && !m.contains(index-1, 1, "S", "K", "L"):
+		m.add("J")
+	}
+	return m.skip(index, "J")
+}
+
+func (m *metaphone) handleL(index int) int {
+	if m.at(index+1) != 'L' {
+		m.add("L")
+		return index + 1
+	}
+	last := len(m.value) - 1
+	if (index == last-2 && m.contains(index-1, 4, "ILLO", "ILLA", "ALLE")) ||
+		((m.contains(last-1, 2, "AS", "OS") || m.contains(last, 1, "A", "O")) && m.contains(index-1, 4, "ALLE")) {
+		// Spanish, e.g. "cabrillo", "gallegos"
+		m.addPrimary("L")
+	} else {
+		m.add("L")
+	}
+	return index + 2
+}
+
+func (m *metaphone) handleS(index int) int {
+	switch {
+	case m.contains(index-1, 3, "ISL", "YSL"):
+		// "island", "isle", "carlisle", "carlysle"
+		return index + 1
+	case index == 0 && m.contains(index, 5, "SUGAR"):
+		m.addBoth("X", "S")
+		return index + 1
+	case m.contains(index, 2, "SH"):
+		if m.contains(index+1, 4, "HEIM", "HOEK", "HOLM", "HOLZ") {
+			// Germanic
+			m.add("S")
+		} else {
+			m.add("X")
+		}
+		return index + 2
+	case m.contains(index, 3, "SIO", "SIA") || m.contains(index, 4, "SIAN"):
+		// Italian and Armenian
+		if m.slavoGermanic {
+			m.add("S")
+		} else {
+			m.addBoth("S", "X")
+		}
+		return index + 3
+	case (index == 0 && m.contains(index+1, 1, "M", "N", "L", "W")) || m.contains(index+1, 1, "Z"):
+		// Germanic and anglicisations, e.g. "smith" matches "schmidt", and -sz- in Slavic languages
+		m.addBoth("S", "X")
+		return m.skip(index, "Z")
+	case m.contains(index, 2, "SC"):
+		return m.handleSC(index)
+	}
+
+	if index == len(m.value)-1 && m.contains(index-2, 2, "AI", "OI") {
+		// French, e.g. "resnais", "artois"
+		m.addAlternate("S")
+	} else {
+		m.add("S")
+	}
+	return m.skip(index, "S", "Z")
+}
+
+func (m *metaphone) handleSC(index int) int {
+	switch {
+	case m.at(index+2) == 'H':
+		// Schlesinger's rule
+		switch {
+		case m.contains(index+3, 2, "ER", "EN"):
+			// "schermerhorn", "schenker"
+			m.addBoth("X", "SK")
+		case m.contains(index+3, 2, "OO", "UY", "ED", "EM"):
+			// Dutch origin, e.g. "school", "schooner"
+			m.add("SK")
+		case index == 0 && !m.isVowel(m.at(3)) && m.at(3) != 'W':
+			m.addBoth("X", "S")
+		default:
+			m.add("X")
+		}
+	case m.contains(index+2, 1, "I", "E", "Y"):
+		m.add("S")
+	default:
+		m.add("SK")
+	}
+	return index + 3
+}
+
+func (m *metaphone) handleT(index int) int {
+	switch {
+	case m.contains(index, 4, "TION"), m.contains(index, 3, "TIA", "TCH"):
+		m.add("X")
+		return index + 3
+	case m.contains(index, 2, "TH") || m.contains(index, 3, "TTH"):
+		if m.contains(index+2, 2, "OM", "AM") || m.contains(0, 4, "VAN ", "VON ") || m.contains(0, 3, "SCH") {
+			// "thomas", "thames" or Germanic
+			m.add("T")
+		} else {
+			m.addBoth("0", "T")
+		}
+		return index + 2
+	}
+	m.add("T")
+	return m.skip(index, "T", "D")
+}
+
+func (m *metaphone) handleW(index int) int {
+	switch {
+	case m.contains(index, 2, "WR"):
+		// Can also be in the middle of a word
+		m.add("R")
+		return index + 2
+	case index == 0 && (m.isVowel(m.at(index+1)) || m.contains(index, 2, "WH")):
+		if m.isVowel(m.at(index + 1)) {
+			// "Wasserman" matches "Vasserman"
+			m.addBoth("A", "F")
+		} else {
+			// "Uomo" matches "Womo"
+			m.add("A")
+		}
+		return index + 1
+	case (index == len(m.value)-1 && m.isVowel(m.at(index-1))) ||
+		m.contains(index-1, 5, "EWSKI", "EWSKY", "OWSKI", "OWSKY") || m.contains(0, 3, "SCH"):
+		// "Arnow" matches "Arnoff"
+		m.addAlternate("F")
+		return index + 1
+	case m.contains(index, 4, "WICZ", "WITZ"):
+		// Polish, e.g. "filipowicz"
+		m.addBoth("TS", "FX")
+		return index + 4
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleX(index int) int {
+	if index == 0 {
+		m.add("S")
+		return index + 1
+	}
+	if !(index == len(m.value)-1 && (m.contains(index-3, 3, "IAU", "EAU") || m.contains(index-2, 2, "AU", "OU"))) {
+		// Not French, e.g. "breaux"
+		m.add("KS")
+	}
+	return m.skip(index, "C", "X")
+}
+
+func (m *metaphone) handleZ(index int) int {
+	if m.at(index+1) == 'H' {
+		// Chinese pinyin, e.g. "zhao"
+		m.add("J")
+		return index + 2
+	}
+	if m.contains(index+1, 2, "ZO", "ZI", "ZA") || (m.slavoGermanic && index > 0 && m.at(index-1) != 'T') {
+		m.addBoth("S", "TS")
+	} else {
+		m.add("S")
+	}
+	return m.skip(index, "Z")
+}
+
+func (m *metaphone) conditionC0(index int) bool {
+	switch {
+	case m.contains(index, 4, "CHIA"):
+		return true
+	case index <= 1, m.isVowel(m.at(index - 2)), !m.contains(index-1, 3, "ACH"):
+		return false
+	}
+	c := m.at(index + 2)
+	return (c != 'I' && c != 'E') || m.contains(index-2, 6, "BACHER", "MACHER")
+}
+
+func (m *metaphone) conditionCH0(index int) bool {
+	if index != 0 {
+		return false
+	}
+	if !m.contains(index+1, 5, "HARAC", "HARIS") && !m.contains(index+1, 3, "HOR", "HYM", "HIA", "HEM") {
+		return false
+	}
+	return !m.contains(0, 5, "CHORE")
+}
+
+func (m *metaphone) conditionCH1(index int) bool {
+	return m.contains(0, 4, "VAN ", "VON ") || m.contains(0, 3, "SCH") ||
+		m.contains(index-2, 6, "ORCHES", "ARCHIT", "ORCHID") ||
+		m.contains(index+2, 1, "T", "S") ||
+		((m.contains(index-1, 1, "A", "O", "U", "E") || index == 0) &&
+			(m.contains(index+2, 1, "L", "R", "N", "M", "B", "H", "F", "V", "W", " ") || index+1 == len(m.value)-1))
+}
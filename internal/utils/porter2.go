@@ -0,0 +1,316 @@
+package utils
+
+import (
+	"strings"
+)
+
+// porter2Exceptions are words with irregular stems, and words that are left as is.
+var porter2Exceptions = map[string]string{
+	"skis":   "ski",
+	"skies":  "sky",
+	"dying":  "die",
+	"lying":  "lie",
+	"tying":  "tie",
+	"idly":   "idl",
+	"gently": "gentl",
+	"ugly":   "ugli",
+	"early":  "earli",
+	"only":   "onli",
+	"singly": "singl",
+	"sky":    "sky",
+	"news":   "news",
+	"howe":   "howe",
+	"atlas":  "atlas",
+	"cosmos": "cosmos",
+	"bias":   "bias",
+	"andes":  "andes",
+}
+
+// porter2Step1aExceptions are left as is after step 1a.
+var porter2Step1aExceptions = map[string]struct{}{
+	"inning": {}, "outing": {}, "canning": {}, "herring": {}, "earring": {},
+	"proceed": {}, "exceed": {}, "succeed": {},
+}
+
+var porter2Step2Suffixes = []struct{ suffix, replacement string }{
+	{"ization", "ize"}, {"ational", "ate"}, {"fulness", "ful"}, {"ousness", "ous"}, {"iveness", "ive"},
+	{"tional", "tion"}, {"biliti", "ble"}, {"lessli", "less"},
+	{"entli", "ent"}, {"ation", "ate"}, {"alism", "al"}, {"aliti", "al"}, {"ousli", "ous"},
+	{"iviti", "ive"}, {"fulli", "ful"},
+	{"enci", "ence"}, {"anci", "ance"}, {"abli", "able"}, {"izer", "ize"}, {"ator", "ate"}, {"alli", "al"},
+	{"bli", "ble"}, {"ogi", "og"},
+	{"li", ""},
+}
+
+var porter2Step3Suffixes = []struct{ suffix, replacement string }{
+	{"ational", "ate"}, {"tional", "tion"}, {"alize", "al"}, {"icate", "ic"}, {"iciti", "ic"},
+	{"ative", ""}, {"ical", "ic"}, {"ness", ""}, {"ful", ""},
+}
+
+var porter2Step4Suffixes = []string{
+	"ement", "ance", "ence", "able", "ible", "ment", "ant", "ent", "ism", "ate", "iti", "ous", "ive",
+	"ize", "ion", "al", "er", "ic",
+}
+
+// StemEnglish reduces a lowercase English word to its stem with the Porter2 (Snowball English) algorithm,
+// e.g. "running" to "run" and "abilities" to "abil". Words containing other characters than ASCII
+// letters and apostrophes are returned as is.
+func StemEnglish(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if c := word[i]; (c < 'a' || c > 'z') && c != '\'' {
+			return word
+		}
+	}
+	if stem, found := porter2Exceptions[word]; found {
+		return stem
+	}
+
+	w := []byte(strings.TrimPrefix(word, "'"))
+	if len(w) <= 2 {
+		return string(w)
+	}
+
+	// Mark y's that act as consonants
+	for i := range w {
+		if w[i] == 'y' && (i == 0 || isPorter2Vowel(w[i-1])) {
+			w[i] = 'Y'
+		}
+	}
+
+	r1, r2 := porter2Regions(w)
+
+	w = porter2Step0(w)
+	w = porter2Step1a(w)
+	if _, found := porter2Step1aExceptions[string(w)]; found {
+		return string(w)
+	}
+	w = porter2Step1b(w, r1)
+	w = porter2Step1c(w)
+	w = porter2Step2(w, r1)
+	w = porter2Step3(w, r1, r2)
+	w = porter2Step4(w, r2)
+	w = porter2Step5(w, r1, r2)
+
+	for i := range w {
+		if w[i] == 'Y' {
+			w[i] = 'y'
+		}
+	}
+	return string(w)
+}
+
+func isPorter2Vowel(c byte) bool {
+	switch c {
+	case 'a', 'e', 'i', 'o', 'u', 'y':
+		return true
+	}
+	return false
+}
+
+// porter2Regions returns the start of the R1 and R2 regions of the word.
+func porter2Regions(w []byte) (int, int) {
+	r1 := len(w)
+	s := string(w)
+	switch {
+	case strings.HasPrefix(s, "gener"), strings.HasPrefix(s, "arsen"):
+		r1 = 5
+	case strings.HasPrefix(s, "commun"):
+		r1 = 6
+	default:
+		r1 = porter2RegionAfter(w, 0)
+	}
+	return r1, porter2RegionAfter(w, r1)
+}
+
+// porter2RegionAfter returns the position after the first non-vowel following a vowel from start.
+func porter2RegionAfter(w []byte, start int) int {
+	for i := start + 1; i < len(w); i++ {
+		if !isPorter2Vowel(w[i]) && isPorter2Vowel(w[i-1]) {
+			return i + 1
+		}
+	}
+	return len(w)
+}
+
+// isPorter2ShortSyllable returns true if the word ends in a short syllable at position end.
+func isPorter2ShortSyllable(w []byte) bool {
+	n := len(w)
+	if n == 2 {
+		return isPorter2Vowel(w[0]) && !isPorter2Vowel(w[1])
+	}
+	if n < 3 {
+		return false
+	}
+	last := w[n-1]
+	return !isPorter2Vowel(w[n-3]) && isPorter2Vowel(w[n-2]) && !isPorter2Vowel(last) &&
+		last != 'w' && last != 'x' && last != 'Y'
+}
+
+// isPorter2Short returns true if the word ends in a short syllable and R1 is empty.
+func isPorter2Short(w []byte, r1 int) bool {
+	return r1 >= len(w) && isPorter2ShortSyllable(w)
+}
+
+func hasPorter2Vowel(w []byte) bool {
+	for _, c := range w {
+		if isPorter2Vowel(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasSuffix(w []byte, suffix string) bool {
+	return len(w) >= len(suffix) && string(w[len(w)-len(suffix):]) == suffix
+}
+
+func porter2Step0(w []byte) []byte {
+	for _, suffix := range []string{"'s'", "'s", "'"} {
+		if hasSuffix(w, suffix) {
+			return w[:len(w)-len(suffix)]
+		}
+	}
+	return w
+}
+
+func porter2Step1a(w []byte) []byte {
+	switch {
+	case hasSuffix(w, "sses"):
+		return w[:len(w)-2]
+	case hasSuffix(w, "ied"), hasSuffix(w, "ies"):
+		if len(w) > 4 {
+			return append(w[:len(w)-3], 'i')
+		}
+		return append(w[:len(w)-3], 'i', 'e')
+	case hasSuffix(w, "us"), hasSuffix(w, "ss"):
+		return w
+	case hasSuffix(w, "s"):
+		// Delete if the preceding part contains a vowel not immediately before the s
+		if len(w) >= 3 && hasPorter2Vowel(w[:len(w)-2]) {
+			return w[:len(w)-1]
+		}
+	}
+	return w
+}
+
+func porter2Step1b(w []byte, r1 int) []byte {
+	for _, suffix := range []string{"eedly", "eed"} {
+		if hasSuffix(w, suffix) {
+			if len(w)-len(suffix) >= r1 {
+				return append(w[:len(w)-len(suffix)], 'e', 'e')
+			}
+			return w
+		}
+	}
+
+	for _, suffix := range []string{"ingly", "edly", "ing", "ed"} {
+		if !hasSuffix(w, suffix) {
+			continue
+		}
+		stem := w[:len(w)-len(suffix)]
+		if !hasPorter2Vowel(stem) {
+			return w
+		}
+		switch {
+		case hasSuffix(stem, "at"), hasSuffix(stem, "bl"), hasSuffix(stem, "iz"):
+			return append(stem, 'e')
+		case porter2EndsInDouble(stem):
+			return stem[:len(stem)-1]
+		case isPorter2Short(stem, r1):
+			return append(stem, 'e')
+		}
+		return stem
+	}
+	return w
+}
+
+func porter2EndsInDouble(w []byte) bool {
+	for _, double := range []string{"bb", "dd", "ff", "gg", "mm", "nn", "pp", "rr", "tt"} {
+		if hasSuffix(w, double) {
+			return true
+		}
+	}
+	return false
+}
+
+func porter2Step1c(w []byte) []byte {
+	n := len(w)
+	if n > 2 && (w[n-1] == 'y' || w[n-1] == 'Y') && !isPorter2Vowel(w[n-2]) {
+		w[n-1] = 'i'
+	}
+	return w
+}
+
+func porter2Step2(w []byte, r1 int) []byte {
+	for _, s := range porter2Step2Suffixes {
+		if !hasSuffix(w, s.suffix) {
+			continue
+		}
+		stem := w[:len(w)-len(s.suffix)]
+		if len(stem) < r1 {
+			return w
+		}
+		switch s.suffix {
+		case "ogi":
+			if !hasSuffix(stem, "l") {
+				return w
+			}
+		case "li":
+			if len(stem) == 0 || !strings.ContainsRune("cdeghkmnrt", rune(stem[len(stem)-1])) {
+				return w
+			}
+		}
+		return append(stem, s.replacement...)
+	}
+	return w
+}
+
+func porter2Step3(w []byte, r1, r2 int) []byte {
+	for _, s := range porter2Step3Suffixes {
+		if !hasSuffix(w, s.suffix) {
+			continue
+		}
+		stem := w[:len(w)-len(s.suffix)]
+		if len(stem) < r1 || (s.suffix == "ative" && len(stem) < r2) {
+			return w
+		}
+		return append(stem, s.replacement...)
+	}
+	return w
+}
+
+func porter2Step4(w []byte, r2 int) []byte {
+	for _, suffix := range porter2Step4Suffixes {
+		if !hasSuffix(w, suffix) {
+			continue
+		}
+		stem := w[:len(w)-len(suffix)]
+		if len(stem) < r2 {
+			return w
+		}
+		if suffix == "ion" && !hasSuffix(stem, "s") && !hasSuffix(stem, "t") {
+			return w
+		}
+		return stem
+	}
+	return w
+}
+
+func porter2Step5(w []byte, r1, r2 int) []byte {
+	n := len(w)
+	switch {
+	case hasSuffix(w, "e"):
+		stem := w[:n-1]
+		if n-1 >= r2 || (n-1 >= r1 && !isPorter2ShortSyllable(stem)) {
+			return stem
+		}
+	case hasSuffix(w, "l"):
+		if n-1 >= r2 && hasSuffix(w[:n-1], "l") {
+			return w[:n-1]
+		}
+	}
+	return w
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"strings"
+)
+
+// soundexCodes maps the letters A to Z to their Soundex digits, with 0 for vowels and '-' for H and W.
+const soundexCodes = "0123012-02245501262301-202"
+
+// Soundex returns the American Soundex code of a word, the upper case first letter followed by up to
+// three digits. Unlike the usual form, codes are not padded with zeros, so that the code of a prefix of
+// a word is usually a prefix of the code of the word. Characters other than ASCII letters are ignored,
+// and the code is empty if the word has none.
+func Soundex(word string) string {
+	var b strings.Builder
+	var last byte
+	for i := 0; i < len(word) && b.Len() < 4; i++ {
+		c := word[i]
+		if c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		if c < 'A' || c > 'Z' {
+			continue
+		}
+		code := soundexCodes[c-'A']
+		switch {
+		case b.Len() == 0:
+			b.WriteByte(c)
+		case code == '-':
+			// H and W do not separate letters with the same code
+			continue
+		case code != '0' && code != last:
+			b.WriteByte(code)
+		}
+		last = code
+	}
+	return b.String()
+}
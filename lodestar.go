@@ -1,15 +1,41 @@
 package lodestar
 
 import (
+	"runtime"
+	"sync"
+
 	iradix "github.com/hashicorp/go-immutable-radix/v2"
 )
 
-type invertedIndex[T IndexableItem] map[string][]T
+type invertedIndex[T IndexableItem] map[string][]posting[T]
 
 // Index represents an immutable text search index.
 type Index[T IndexableItem] struct {
-	index     *iradix.Tree[[]T]
-	tokenizer Tokenizer
+	index      *iradix.Tree[[]posting[T]]
+	tokenizer  Tokenizer
+	tieBreaker TieBreaker
+
+	// Sequence number assigned to the next indexed item
+	nextSeq uint64
+
+	// Whether any posting has a weight other than 1, so that an item may score differently per token
+	weighted bool
+
+	// Deletion dictionary mapping deleted variants to vocabulary tokens, only set if SymSpell is enabled
+	deletes  *iradix.Tree[[]string]
+	symSpell *SymSpellConfig
+
+	// Pool of reusable search buffers, shared by all versions of the index
+	buffers          *sync.Pool
+	batchConcurrency int
+
+	// Optional dynamic boosts applied at search time
+	overlay  *RankOverlay
+	feedback *selectionFeedback
+
+	// Optional synonym graph used to search alternatives of queries
+	synonyms       *Synonyms
+	synonymPenalty float64
 }
 
 // New creates a new empty Index. If no Tokenizer is provided, it uses the default tokenizer,
@@ -25,10 +51,29 @@ func New[T IndexableItem](opts ...Option) *Index[T] {
 		config.Tokenizer = &DefaultTokenizer{}
 	}
 
-	return &Index[T]{
-		index:     iradix.New[[]T](),
-		tokenizer: config.Tokenizer,
+	// Fan out batch queries over all available CPUs by default
+	if config.BatchConcurrency <= 0 {
+		config.BatchConcurrency = runtime.GOMAXPROCS(0)
+	}
+
+	idx := &Index[T]{
+		index:            iradix.New[[]posting[T]](),
+		tokenizer:        config.Tokenizer,
+		tieBreaker:       config.TieBreaker,
+		symSpell:         config.SymSpell,
+		buffers:          &sync.Pool{},
+		batchConcurrency: config.BatchConcurrency,
+		overlay:          config.RankOverlay,
+		synonyms:         config.Synonyms,
+		synonymPenalty:   config.SynonymPenalty,
+	}
+	if config.Feedback != nil {
+		idx.feedback = newSelectionFeedback(*config.Feedback)
+	}
+	if config.SymSpell != nil {
+		idx.deletes = iradix.New[[]string]()
 	}
+	return idx
 }
 
 // Len returns the number of items in the index radix tree
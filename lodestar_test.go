@@ -39,6 +39,15 @@ func setupIndexWithItems(items []*ExampleItem) *Index[*ExampleItem] {
 	return index
 }
 
+// resultTexts returns the first indexed value of each result, e.g. the Text of an ExampleItem.
+func resultTexts[T IndexableItem](results []T) []string {
+	texts := make([]string, 0, len(results))
+	for _, r := range results {
+		texts = append(texts, r.GetValuesForIndexing()[0])
+	}
+	return texts
+}
+
 func TestNew(t *testing.T) {
 	index := New[*ExampleItem]()
 	if index == nil {
@@ -0,0 +1,190 @@
+package lodestar
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// tokenNamespaceMarker starts the tokens of a separate namespace, which are not matched by the
+	// prefix of a query itself, e.g. n-grams.
+	tokenNamespaceMarker = "\x00"
+
+	// nGramNamespace is the namespace of n-gram tokens.
+	nGramNamespace = tokenNamespaceMarker + "ngram" + tokenNamespaceMarker
+
+	defaultNGramPenalty = 0.5
+)
+
+// isNamespacedToken returns true if the token belongs to a separate namespace.
+func isNamespacedToken(token string) bool {
+	return strings.HasPrefix(token, tokenNamespaceMarker)
+}
+
+// NGramMode determines where n-grams start within a word.
+type NGramMode int
+
+const (
+	// NGramEdge generates n-grams from the start of every word, where words are runs of letters and
+	// digits, e.g. "123" in "abc-123.x".
+	NGramEdge NGramMode = iota
+
+	// NGramInner generates n-grams from every position within a word, e.g. "234" in "a12345".
+	NGramInner
+)
+
+// NGramTokenizer wraps a Tokenizer to match queries from arbitrary positions within words, e.g. for part
+// numbers or hashes. N-grams are indexed in a separate namespace alongside the tokens of the wrapped
+// tokenizer, so that a match on an n-gram is told apart from a match on a token, and is ranked lower.
+//
+// As queries are matched by prefix, only the longest n-gram at each position is indexed, with n-grams
+// as short as MinGram at the end of words. Queries shorter than MinGram do not match n-grams, and
+// queries longer than MaxGram match the n-grams of their start, keeping the items containing the query.
+type NGramTokenizer struct {
+	tokenizerWrapper
+
+	MinGram int
+	MaxGram int
+	Mode    NGramMode
+
+	// Penalty reduces the scores of n-gram matches, from 0 for no penalty to 1.
+	Penalty float64
+}
+
+// NewNGramTokenizer creates an NGramTokenizer wrapping the tokenizer, with n-grams of minGram to maxGram
+// characters and a penalty of 0.5. If tokenizer is nil, the DefaultTokenizer is used.
+// minGram is at least 1, and maxGram at least minGram.
+func NewNGramTokenizer(tokenizer Tokenizer, minGram, maxGram int, mode NGramMode) *NGramTokenizer {
+	if tokenizer == nil {
+		tokenizer = &DefaultTokenizer{}
+	}
+	minGram = max(minGram, 1)
+	return &NGramTokenizer{
+		tokenizerWrapper: tokenizerWrapper{Tokenizer: tokenizer},
+		MinGram:          minGram,
+		MaxGram:          max(maxGram, minGram),
+		Mode:             mode,
+		Penalty:          defaultNGramPenalty,
+	}
+}
+
+func (t *NGramTokenizer) Tokenize(item IndexableItem) []string {
+	tokens := t.Tokenizer.Tokenize(item)
+	for _, value := range item.GetValuesForIndexing() {
+		if grams := t.nGrams(t.Tokenizer.NormalizeString(value)); grams != nil {
+			tokens = mergeUniqueTokens(tokens, grams)
+		}
+	}
+	return tokens
+}
+
+// queryForms returns the forms of the wrapped tokenizer, and the n-gram form of queries of at least
+// MinGram characters.
+func (t *NGramTokenizer) queryForms(normalized string) []queryForm {
+	forms := t.tokenizerWrapper.queryForms(normalized)
+
+	length := utf8.RuneCountInString(normalized)
+	if length < t.MinGram || strings.IndexFunc(normalized, isNGramSeparator) >= 0 {
+		// N-grams never span words
+		return forms
+	}
+
+	form := queryForm{prefix: nGramNamespace + normalized, factor: 1 - min(max(t.Penalty, 0), 1)}
+	if length > t.MaxGram {
+		runes := []rune(normalized)
+		form.prefix = nGramNamespace + string(runes[:t.MaxGram])
+		form.match = func(item IndexableItem) bool {
+			for _, value := range item.GetValuesForIndexing() {
+				if strings.Contains(t.Tokenizer.NormalizeString(value), normalized) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return append(forms, form)
+}
+
+// Analyze returns the tokens of the wrapped tokenizer, followed by the n-grams of the value.
+func (t *NGramTokenizer) Analyze(value string) []TokenInfo {
+	tokens := t.tokenizerWrapper.Analyze(value)
+	tokens = append(tokens, t.nGramTokens(t.Tokenizer.NormalizeString(value))...)
+	return uniqueTokenInfos(tokens)
+}
+
+// tokenVariation returns the rule of the wrapped tokenizer that produced the token, or
+// TokenVariationNGram if it is an n-gram of the value.
+func (t *NGramTokenizer) tokenVariation(value string, token string) TokenVariation {
+	if variation := t.tokenizerWrapper.tokenVariation(value, token); variation != TokenVariationUnknown {
+		return variation
+	}
+	for _, gram := range t.nGrams(t.Tokenizer.NormalizeString(value)) {
+		if gram == token {
+			return TokenVariationNGram
+		}
+	}
+	return TokenVariationUnknown
+}
+
+// nGrams returns the namespaced n-grams of the words in the value.
+func (t *NGramTokenizer) nGrams(value string) []string {
+	grams := t.nGramTokens(value)
+	if grams == nil {
+		return nil
+	}
+	return tokenTexts(grams)
+}
+
+// nGramTokens returns the namespaced n-grams of the words in the value, positioned at the whitespace
+// separated word containing them.
+func (t *NGramTokenizer) nGramTokens(value string) []TokenInfo {
+	var grams []TokenInfo
+	position, inWord, start := -1, false, -1
+	flush := func(end int) {
+		if start >= 0 {
+			grams = append(grams, t.wordNGrams(value[start:end], position)...)
+			start = -1
+		}
+	}
+	for i, r := range value {
+		if unicode.IsSpace(r) {
+			inWord = false
+		} else if !inWord {
+			position, inWord = position+1, true
+		}
+		if isNGramSeparator(r) {
+			flush(i)
+		} else if start < 0 {
+			start = i
+		}
+	}
+	flush(len(value))
+	return grams
+}
+
+// wordNGrams returns the namespaced n-grams of a word without separators.
+func (t *NGramTokenizer) wordNGrams(word string, position int) []TokenInfo {
+	var grams []TokenInfo
+	runes := []rune(word)
+	for start := range runes {
+		if t.Mode == NGramEdge && start > 0 {
+			break
+		}
+		end := min(start+t.MaxGram, len(runes))
+		if end-start < t.MinGram {
+			break
+		}
+		grams = append(grams, TokenInfo{
+			Text:      nGramNamespace + string(runes[start:end]),
+			Position:  position,
+			Variation: TokenVariationNGram,
+		})
+	}
+	return grams
+}
+
+// isNGramSeparator returns true if the rune separates words for n-grams.
+func isNGramSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
@@ -0,0 +1,64 @@
+package lodestar
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNGramTokenizer(t *testing.T) {
+	tests := []struct {
+		mode NGramMode
+		want []string
+	}{
+		{mode: NGramEdge, want: []string{nGramNamespace + "abc", nGramNamespace + "x9"}},
+		{mode: NGramInner, want: []string{
+			nGramNamespace + "abc", nGramNamespace + "bcd", nGramNamespace + "cd", nGramNamespace + "x9",
+		}},
+	}
+	for _, tt := range tests {
+		tr := NewNGramTokenizer(nil, 2, 3, tt.mode)
+		got := tr.Tokenize(&valueItem{value: "ABCD.x9"})
+		want := append(tt.want, "abcd.x9")
+		if !slices.Equal(got, want) {
+			t.Errorf("Tokenize() with mode %v = %q, want %q", tt.mode, got, want)
+		}
+	}
+}
+
+func TestPrefixSearchNGrams(t *testing.T) {
+	items := []*ExampleItem{
+		{Text: "XK2045B", Rank: 10},
+		{Text: "2045 model", Rank: 6},
+		{Text: "a3f9c2e1", Rank: 5},
+	}
+	index, _ := New[*ExampleItem](WithTokenizer(NewNGramTokenizer(nil, 2, 4, NGramInner))).IndexItems(items)
+
+	for query, want := range map[string][]string{
+		// The word-start match of "2045 model" scores 6, above the n-gram match of "XK2045B" at 10 * 0.5 = 5
+		"2045":    {"2045 model", "XK2045B"},
+		"204":     {"2045 model", "XK2045B"},
+		"f9c":     {"a3f9c2e1"},
+		"f9c2e":   {"a3f9c2e1"},
+		"f9c2x":   nil,
+		"5-b":     nil,
+		"x":       {"XK2045B"},
+		"2045 mo": {"2045 model"},
+	} {
+		results, _ := index.PrefixSearch(query, 0, nil)
+		got := resultTexts(results)
+		if !slices.Equal(got, want) {
+			t.Errorf("PrefixSearch(%q) = %v, want %v", query, got, want)
+		}
+	}
+
+	explanation := index.Explain("2045", 0, nil)
+	if r := explanation.Results[1]; r.Variation != TokenVariationNGram || r.Alternative != nGramNamespace+"2045" || r.Score != 5 {
+		t.Errorf("Unexpected explanation: %+v", r)
+	}
+
+	for _, suggestion := range index.Suggest("204", 0) {
+		if isNamespacedToken(suggestion.Text) {
+			t.Errorf("Suggested n-gram token %q", suggestion.Text)
+		}
+	}
+}
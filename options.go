@@ -6,6 +6,39 @@ type Option func(*Config)
 // Config holds configuration for the Index.
 type Config struct {
 	Tokenizer Tokenizer
+
+	// TieBreaker determines how items with equal ranks are ordered. Defaults to insertion order.
+	TieBreaker TieBreaker
+
+	// SymSpell enables building a deletion dictionary at index time for spelling suggestions.
+	// If nil, Suggest falls back to scanning the whole vocabulary.
+	SymSpell *SymSpellConfig
+
+	// BatchConcurrency is the maximum number of goroutines used by PrefixSearchBatch.
+	// Defaults to GOMAXPROCS.
+	BatchConcurrency int
+
+	// RankOverlay holds dynamic boosts that are added to the scores of items at search time.
+	RankOverlay *RankOverlay
+
+	// Feedback enables learning per-query boosts from selections recorded with RecordSelection.
+	Feedback *FeedbackConfig
+
+	// Synonyms rewrites queries into alternatives that are searched as well.
+	Synonyms *Synonyms
+
+	// SynonymPenalty reduces the scores of matches of synonyms, from 0 for no penalty to 1.
+	SynonymPenalty float64
+}
+
+// SymSpellConfig configures the SymSpell-style deletion dictionary used by Suggest.
+type SymSpellConfig struct {
+	// MaxEditDistance is the maximum edit distance between a query and a suggested token.
+	MaxEditDistance int
+
+	// PrefixLength limits deletions to the first PrefixLength characters of each token,
+	// trading a little recall for a much smaller dictionary.
+	PrefixLength int
 }
 
 // WithTokenizer returns an Option that sets the tokenizer for the index.
@@ -14,3 +47,135 @@ func WithTokenizer(tokenizer Tokenizer) Option {
 		c.Tokenizer = tokenizer
 	}
 }
+
+// WithTieBreaker returns an Option that sets how items with equal ranks are ordered.
+func WithTieBreaker(tieBreaker TieBreaker) Option {
+	return func(c *Config) {
+		c.TieBreaker = tieBreaker
+	}
+}
+
+// WithSymSpell returns an Option that builds a deletion dictionary at index time, which makes
+// Suggest lookups independent of the vocabulary size.
+// Non-positive values fall back to a max edit distance of 2 and a prefix length of 7.
+func WithSymSpell(maxEditDistance, prefixLength int) Option {
+	return func(c *Config) {
+		if maxEditDistance <= 0 {
+			maxEditDistance = defaultSuggestMaxDistance
+		}
+		if prefixLength <= 0 {
+			prefixLength = defaultSymSpellPrefixLength
+		}
+		c.SymSpell = &SymSpellConfig{
+			MaxEditDistance: maxEditDistance,
+			PrefixLength:    prefixLength,
+		}
+	}
+}
+
+// WithBatchConcurrency returns an Option that sets the maximum number of goroutines used by PrefixSearchBatch.
+// A concurrency of 1 runs all queries sequentially on the calling goroutine.
+func WithBatchConcurrency(concurrency int) Option {
+	return func(c *Config) {
+		c.BatchConcurrency = concurrency
+	}
+}
+
+// WithRankOverlay returns an Option that applies the boosts of the overlay at search time.
+// The overlay is shared by all versions of the index, so boosts can be updated without reindexing.
+func WithRankOverlay(overlay *RankOverlay) Option {
+	return func(c *Config) {
+		c.RankOverlay = overlay
+	}
+}
+
+// WithSelectionFeedback returns an Option that learns boosts from the selections recorded with
+// RecordSelection, and adds them to the scores of the selected items in searches for the same query.
+// The learned boosts are shared by all versions of the index.
+func WithSelectionFeedback(config FeedbackConfig) Option {
+	return func(c *Config) {
+		c.Feedback = &config
+	}
+}
+
+// WithSynonyms returns an Option that searches the alternatives of each query from the synonym graph
+// as well, merging their matches with the matches of the query itself.
+// The scores of synonym matches are multiplied by 1 - penalty, so that a penalty of 0 ranks them
+// equal to literal matches, and a penalty of 0.2 ranks them at 80%.
+func WithSynonyms(synonyms *Synonyms, penalty float64) Option {
+	return func(c *Config) {
+		c.Synonyms = synonyms
+		c.SynonymPenalty = min(max(penalty, 0), 1)
+	}
+}
+
+// SearchOption represents a configuration option for a single search.
+type SearchOption[T IndexableItem] func(*searchOptions[T])
+
+// searchOptions holds the per-search configuration.
+type searchOptions[T IndexableItem] struct {
+	// Collapse results on a group key, keeping the best groupLimit items per group
+	groupKey   func(item T) any
+	groupLimit int
+
+	// Diversify the top results on a key, trading rank fidelity for variety
+	diversityKey    func(item T) any
+	diversityLambda float64
+
+	// Search the phonetic codes of the query as well
+	phonetic        bool
+	phoneticPenalty float64
+}
+
+// newSearchOptions applies the search options over the defaults.
+func newSearchOptions[T IndexableItem](opts []SearchOption[T]) *searchOptions[T] {
+	options := &searchOptions[T]{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithGroupBy returns a SearchOption that collapses results on the key returned by keyFn,
+// keeping at most perGroup items per group. Groups are returned in order of their best item,
+// with the items of each group kept together.
+// If keyFn is nil, the GroupKey of items implementing the Groupable interface is used.
+func WithGroupBy[T IndexableItem](keyFn func(item T) any, perGroup int) SearchOption[T] {
+	return func(o *searchOptions[T]) {
+		if keyFn == nil {
+			keyFn = groupableKey[T]
+		}
+		o.groupKey = keyFn
+		o.groupLimit = max(perGroup, 1)
+	}
+}
+
+// WithDiversity returns a SearchOption that diversifies the top results so that items sharing the key
+// returned by keyFn do not dominate them. Results are selected greedily in an MMR-like fashion from a
+// pool of the best candidates, with at most limit candidates per key, scoring each candidate by
+//
+//	lambda * normalized rank - (1 - lambda) * number of selected items with the same key
+//
+// A lambda of 1 keeps the rank order, while a lambda of 0 takes turns between keys.
+// If keyFn is nil, the GroupKey of items implementing the Groupable interface is used.
+func WithDiversity[T IndexableItem](keyFn func(item T) any, lambda float64) SearchOption[T] {
+	return func(o *searchOptions[T]) {
+		if keyFn == nil {
+			keyFn = groupableKey[T]
+		}
+		o.diversityKey = keyFn
+		o.diversityLambda = min(max(lambda, 0), 1)
+	}
+}
+
+// WithPhonetic returns a SearchOption that searches the phonetic codes of the query as well, if the
+// tokenizer is an Analyzer with a PhoneticFilter, so that e.g. "jon smyth" finds "john smith".
+// The scores of phonetic matches are multiplied by 1 - penalty, e.g. halving them with a penalty of 0.5.
+// A penalty of 1 scales them down as far as possible while keeping their rank order, so that they rank
+// below literal matches with a positive rank, but not below literal matches with a rank of 0 or less.
+func WithPhonetic[T IndexableItem](penalty float64) SearchOption[T] {
+	return func(o *searchOptions[T]) {
+		o.phonetic = true
+		o.phoneticPenalty = min(max(penalty, 0), 1)
+	}
+}
@@ -0,0 +1,110 @@
+package lodestar
+
+import (
+	"cmp"
+)
+
+// Ordered is an optional interface for items whose IDs have a total order, used by TieBreakID
+// to order items with equal ranks.
+type Ordered interface {
+	// CompareID compares the item's ID with the ID of another item, returning a negative number
+	// if the item should be ordered first, a positive number if it should be ordered last, or zero if equal.
+	CompareID(otherID any) int
+}
+
+// TieBreaker determines how items with equal ranks are ordered.
+// Ties that remain after applying the tie breaker are always broken by insertion order,
+// so equal inputs give identical outputs.
+type TieBreaker int
+
+const (
+	// TieBreakInsertionOrder orders items with equal ranks by the order they were indexed in.
+	TieBreakInsertionOrder TieBreaker = iota
+
+	// TieBreakID orders items with equal ranks by their IDs, if the items implement the Ordered interface.
+	TieBreakID
+
+	// TieBreakTokenLength orders items with equal ranks by the length of the token they matched, shortest first.
+	TieBreakTokenLength
+)
+
+// posting represents an item in the posting list of a token.
+type posting[T IndexableItem] struct {
+	item T
+
+	// Weight of the value that produced the token, 1 for unweighted items
+	weight float64
+
+	// Insertion sequence number of the item, used as the final tie breaker
+	seq uint64
+}
+
+// postingItems returns the items of a posting list.
+func postingItems[T IndexableItem](postings []posting[T]) []T {
+	if postings == nil {
+		return nil
+	}
+	items := make([]T, len(postings))
+	for i, p := range postings {
+		items[i] = p.item
+	}
+	return items
+}
+
+// compareResults returns a negative number if a should be ordered before b,
+// i.e. a has a higher score or wins the tie break.
+func compareResults[T IndexableItem](tb TieBreaker, a, b result[T]) int {
+	if c := cmp.Compare(b.Score, a.Score); c != 0 {
+		return c
+	}
+
+	switch tb {
+	case TieBreakID:
+		if ordered, ok := any(a.Value).(Ordered); ok {
+			if _, ok := any(b.Value).(Ordered); ok {
+				if c := ordered.CompareID(b.Value.GetID()); c != 0 {
+					return c
+				}
+			}
+		}
+	case TieBreakTokenLength:
+		if c := cmp.Compare(len(a.Token), len(b.Token)); c != 0 {
+			return c
+		}
+	}
+
+	return cmp.Compare(a.seq, b.seq)
+}
+
+// comparePostings orders the postings of a token in the same way as their search results.
+func comparePostings[T IndexableItem](tb TieBreaker, a, b posting[T]) int {
+	return compareResults(tb, a.result(""), b.result(""))
+}
+
+// minScoreFactor bounds the factors that scores are scaled by, see scaleScore.
+const minScoreFactor = 1e-9
+
+// scaleScore scales a score by a weight or penalty factor. Negative scores are divided by the factor
+// instead of multiplied, so that a lower factor always lowers the score, also for negative ranks. The
+// factor is kept above zero, so that scaling never collapses scores into a tie and preserves the order
+// of the presorted postings.
+func scaleScore(score, factor float64) float64 {
+	factor = max(factor, minScoreFactor)
+	if score >= 0 {
+		return score * factor
+	}
+	return score / factor
+}
+
+// result returns the search result for the posting when matched through the token.
+func (p posting[T]) result(token string) result[T] {
+	rank := p.item.GetRank()
+	return result[T]{
+		Value:  p.item,
+		Rank:   rank,
+		Weight: p.weight,
+		Score:  scaleScore(float64(rank), p.weight),
+		Token:  token,
+		seq:    p.seq,
+	}
+}
@@ -0,0 +1,63 @@
+package lodestar
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+)
+
+type orderedItem struct {
+	ExampleItem
+	ID int
+}
+
+func (o *orderedItem) GetID() any {
+	return o.ID
+}
+
+func (o *orderedItem) CompareID(otherID any) int {
+	return cmp.Compare(o.ID, otherID.(int))
+}
+
+func TestPrefixSearchTieBreakers(t *testing.T) {
+	items := []*orderedItem{
+		{ExampleItem: ExampleItem{Text: "app store", Rank: 5}, ID: 3},
+		{ExampleItem: ExampleItem{Text: "apple", Rank: 5}, ID: 2},
+		{ExampleItem: ExampleItem{Text: "apple pie", Rank: 5}, ID: 4},
+		{ExampleItem: ExampleItem{Text: "apricot", Rank: 9}, ID: 5},
+		{ExampleItem: ExampleItem{Text: "application", Rank: 5}, ID: 1},
+	}
+
+	tests := []struct {
+		name       string
+		tieBreaker TieBreaker
+		want       []int
+	}{
+		{name: "insertion order", tieBreaker: TieBreakInsertionOrder, want: []int{5, 3, 2, 4, 1}},
+		{name: "id", tieBreaker: TieBreakID, want: []int{5, 1, 2, 3, 4}},
+		{name: "token length", tieBreaker: TieBreakTokenLength, want: []int{5, 2, 3, 4, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, _ := New[*orderedItem](WithTieBreaker(tt.tieBreaker)).IndexItems(items)
+
+			// Repeat to catch any dependency on map iteration order
+			for range 20 {
+				for _, limit := range []int{0, 3} {
+					results, _ := index.PrefixSearch("ap", limit, nil)
+					got := make([]int, 0, len(results))
+					for _, r := range results {
+						got = append(got, r.ID)
+					}
+					want := tt.want
+					if limit > 0 {
+						want = want[:limit]
+					}
+					if !slices.Equal(got, want) {
+						t.Fatalf("PrefixSearch('ap', %d) got IDs %v, want %v", limit, got, want)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package lodestar
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// RankOverlay holds dynamic rank boosts by item ID, e.g. from click-through popularity, that are
+// applied at search time without reindexing. Boosts optionally decay exponentially over time.
+//
+// A boost is added to the score of an item, i.e. its rank multiplied by the weight of the matched value.
+// Boosts are capped at the maximum boost of the overlay, which bounds how far a boosted item can
+// overtake the static order so that the search can still stop early.
+//
+// A RankOverlay is safe for concurrent use, and can be shared by all versions of an index.
+type RankOverlay struct {
+	mu     sync.RWMutex
+	boosts map[any]overlayBoost
+
+	maxBoost float64
+	halfLife time.Duration
+
+	// Clock used for decay, overridable for testing
+	now func() time.Time
+}
+
+// overlayBoost is a boost value as of the time it was last updated.
+type overlayBoost struct {
+	value   float64
+	updated time.Time
+}
+
+// NewRankOverlay creates an empty RankOverlay whose boosts are capped at maxBoost.
+// If halfLife > 0, boosts halve every halfLife since they were last updated, otherwise they do not decay.
+func NewRankOverlay(maxBoost float64, halfLife time.Duration) *RankOverlay {
+	return &RankOverlay{
+		boosts:   make(map[any]overlayBoost),
+		maxBoost: max(maxBoost, 0),
+		halfLife: halfLife,
+		now:      time.Now,
+	}
+}
+
+// MaxBoost returns the maximum boost of the overlay.
+func (o *RankOverlay) MaxBoost() float64 {
+	return o.maxBoost
+}
+
+// Set sets the boost of an item, capped at the maximum boost.
+func (o *RankOverlay) Set(id any, boost float64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.boosts[id] = overlayBoost{value: min(boost, o.maxBoost), updated: o.now()}
+}
+
+// Add adds delta to the decayed boost of an item, e.g. 1 for every click, capped at the maximum boost.
+func (o *RankOverlay) Add(id any, delta float64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	now := o.now()
+	boost := o.decay(o.boosts[id], now) + delta
+	o.boosts[id] = overlayBoost{value: min(boost, o.maxBoost), updated: now}
+}
+
+// Boost returns the current, decayed boost of an item, or 0 if it has none.
+func (o *RankOverlay) Boost(id any) float64 {
+	return o.boostAt(id, o.now())
+}
+
+// Delete removes the boost of an item.
+func (o *RankOverlay) Delete(id any) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.boosts, id)
+}
+
+// Prune removes the boosts that have decayed to less than minBoost in magnitude, and returns how many were removed.
+func (o *RankOverlay) Prune(minBoost float64) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	now := o.now()
+	removed := 0
+	for id, boost := range o.boosts {
+		if math.Abs(o.decay(boost, now)) < minBoost {
+			delete(o.boosts, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Len returns the number of items with a boost.
+func (o *RankOverlay) Len() int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return len(o.boosts)
+}
+
+// boostAt returns the boost of an item decayed up to now.
+func (o *RankOverlay) boostAt(id any, now time.Time) float64 {
+	o.mu.RLock()
+	boost, found := o.boosts[id]
+	o.mu.RUnlock()
+	if !found {
+		return 0
+	}
+	return o.decay(boost, now)
+}
+
+// decay returns the value of the boost decayed up to now.
+func (o *RankOverlay) decay(boost overlayBoost, now time.Time) float64 {
+	if o.halfLife <= 0 || boost.value == 0 {
+		return boost.value
+	}
+	elapsed := now.Sub(boost.updated)
+	if elapsed <= 0 {
+		return boost.value
+	}
+	return boost.value * math.Exp2(-float64(elapsed)/float64(o.halfLife))
+}
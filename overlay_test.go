@@ -0,0 +1,82 @@
+package lodestar
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRankOverlayDecay(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	overlay := NewRankOverlay(10, time.Hour)
+	overlay.now = func() time.Time { return clock }
+
+	overlay.Add("a", 4)
+	overlay.Add("a", 4)
+	if got := overlay.Boost("a"); got != 8 {
+		t.Errorf("Boost after two adds = %v, want 8", got)
+	}
+
+	// Boosts are capped at the maximum boost
+	overlay.Add("a", 4)
+	if got := overlay.Boost("a"); got != 10 {
+		t.Errorf("Boost above maximum = %v, want 10", got)
+	}
+
+	clock = clock.Add(time.Hour)
+	if got := overlay.Boost("a"); got != 5 {
+		t.Errorf("Boost after one half-life = %v, want 5", got)
+	}
+
+	// Adding decays the previous boost first
+	overlay.Add("a", 1)
+	clock = clock.Add(2 * time.Hour)
+	if got := overlay.Boost("a"); math.Abs(got-1.5) > 1e-9 {
+		t.Errorf("Boost after two more half-lives = %v, want 1.5", got)
+	}
+
+	overlay.Set("b", 0.5)
+	if removed := overlay.Prune(1); removed != 1 || overlay.Len() != 1 {
+		t.Errorf("Prune removed %d leaving %d, want 1 leaving 1", removed, overlay.Len())
+	}
+	if got := overlay.Boost("b"); got != 0 {
+		t.Errorf("Boost of pruned item = %v, want 0", got)
+	}
+}
+
+func TestPrefixSearchRankOverlay(t *testing.T) {
+	overlay := NewRankOverlay(20, 0)
+	index, _ := New[*ExampleItem](WithRankOverlay(overlay)).IndexItems(testItems)
+
+	results, _ := index.PrefixSearch("app", 2, nil)
+	if got, want := resultTexts(results), []string{"application", "apple"}; !slices.Equal(got, want) {
+		t.Errorf("Search without boosts got %v, want %v", got, want)
+	}
+
+	// The lowest ranked item is boosted above the others, so the search must not stop early
+	overlay.Set(testItems[4].GetID(), 12)
+	overlay.Set(testItems[0].GetID(), -5)
+	results, _ = index.PrefixSearch("app", 2, nil)
+	if got, want := resultTexts(results), []string{"approach", "application"}; !slices.Equal(got, want) {
+		t.Errorf("Search with boosts got %v, want %v", got, want)
+	}
+
+	results, _ = index.PrefixSearch("app", 0, nil)
+	if got, want := resultTexts(results), []string{"approach", "application", "apply", "apple"}; !slices.Equal(got, want) {
+		t.Errorf("Unlimited search with boosts got %v, want %v", got, want)
+	}
+
+	explanation := index.Explain("app", 1, nil)
+	if r := explanation.Results[0]; r.Rank != 5 || r.Boost != 12 || r.Score != 17 {
+		t.Errorf("Unexpected explanation: %+v", r)
+	}
+
+	// Boosts are shared by new versions of the index
+	index, _ = index.IndexItems([]*ExampleItem{{Text: "appetite", Rank: 1}})
+	overlay.Delete(testItems[4].GetID())
+	results, _ = index.PrefixSearch("app", 1, nil)
+	if got, want := resultTexts(results), []string{"application"}; !slices.Equal(got, want) {
+		t.Errorf("Search after removing boost got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,135 @@
+package lodestar
+
+import (
+	"strings"
+
+	"github.com/regalias/lodestar/internal/utils"
+)
+
+const (
+	// phoneticNamespace is the namespace of phonetic tokens.
+	phoneticNamespace = tokenNamespaceMarker + "phonetic" + tokenNamespaceMarker
+
+	// maxPhoneticCodes bounds the number of phonetic tokens a token or query is encoded into.
+	maxPhoneticCodes = 16
+)
+
+// PhoneticEncoder encodes words into codes of how they sound, e.g. "smith" and "smyth" into "SM0".
+type PhoneticEncoder interface {
+	// Encode returns the codes of a lowercase word, or none if the word has nothing to encode.
+	Encode(word string) []string
+}
+
+// DoubleMetaphoneEncoder is a PhoneticEncoder returning the primary and alternate Double Metaphone
+// codes of words, of up to 4 characters.
+type DoubleMetaphoneEncoder struct{}
+
+func (DoubleMetaphoneEncoder) Encode(word string) []string {
+	primary, alternate := utils.DoubleMetaphone(word)
+	switch {
+	case primary == "":
+		return nil
+	case alternate == "" || alternate == primary:
+		return []string{primary}
+	}
+	return []string{primary, alternate}
+}
+
+// SoundexEncoder is a PhoneticEncoder returning the American Soundex code of words, without padding
+// zeros so that the codes of partially typed words prefix the codes of the words.
+type SoundexEncoder struct{}
+
+func (SoundexEncoder) Encode(word string) []string {
+	if code := utils.Soundex(word); code != "" {
+		return []string{code}
+	}
+	return nil
+}
+
+// PhoneticFilter adds the phonetic codes of the words of each token, joined by spaces, in a separate
+// namespace so that they never match literal queries. If encoder is nil, the DoubleMetaphoneEncoder is
+// used. The tokens have the TokenVariationPhonetic variation.
+//
+// Phonetic matches are only searched with the WithPhonetic search option, which encodes the query
+// with the phonetic filters of the Analyzer.
+func PhoneticFilter(encoder PhoneticEncoder) TokenFilter {
+	if encoder == nil {
+		encoder = DoubleMetaphoneEncoder{}
+	}
+	return phoneticFilter{encoder: encoder}
+}
+
+type phoneticFilter struct {
+	encoder PhoneticEncoder
+}
+
+func (f phoneticFilter) FilterTokens(_ string, tokens []TokenInfo) []TokenInfo {
+	filtered := tokens
+	for _, token := range tokens {
+		if isNamespacedToken(token.Text) {
+			continue
+		}
+		for _, code := range f.encode(token.Text) {
+			filtered = append(filtered, TokenInfo{Text: code, Position: token.Position, Variation: TokenVariationPhonetic})
+		}
+	}
+	return filtered
+}
+
+// encode returns the namespaced combinations of the codes of the words in the value.
+// Words without codes are left out.
+func (f phoneticFilter) encode(value string) []string {
+	combinations := []string{""}
+	for _, word := range strings.Fields(value) {
+		codes := f.encoder.Encode(word)
+		if len(codes) == 0 {
+			continue
+		}
+		next := make([]string, 0, min(len(combinations)*len(codes), maxPhoneticCodes))
+		for _, combination := range combinations {
+			for _, code := range codes {
+				if len(next) == maxPhoneticCodes {
+					break
+				}
+				if combination == "" {
+					next = append(next, code)
+				} else {
+					next = append(next, combination+" "+code)
+				}
+			}
+		}
+		combinations = next
+	}
+	if combinations[0] == "" {
+		return nil
+	}
+	for i, combination := range combinations {
+		combinations[i] = phoneticNamespace + combination
+	}
+	return combinations
+}
+
+// phoneticFormer is implemented by tokenizers that encode queries into phonetic tokens.
+type phoneticFormer interface {
+	phoneticForms(normalized string) []string
+}
+
+// tokenizerPhoneticForms returns the phonetic tokens of the normalized query from the tokenizer, if it
+// implements phoneticFormer.
+func tokenizerPhoneticForms(tokenizer Tokenizer, normalized string) []string {
+	if former, ok := tokenizer.(phoneticFormer); ok {
+		return former.phoneticForms(normalized)
+	}
+	return nil
+}
+
+// phoneticForms returns the phonetic tokens of the normalized query from the phonetic filters.
+func (a *Analyzer) phoneticForms(normalized string) []string {
+	var forms []string
+	for _, filter := range a.tokenFilters {
+		if phonetic, ok := filter.(phoneticFilter); ok {
+			forms = append(forms, phonetic.encode(normalized)...)
+		}
+	}
+	return forms
+}
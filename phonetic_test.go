@@ -0,0 +1,92 @@
+package lodestar
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPhoneticEncoders(t *testing.T) {
+	tests := []struct {
+		word      string
+		metaphone []string
+		soundex   []string
+	}{
+		{"smith", []string{"SM0", "XMT"}, []string{"S53"}},
+		{"schmidt", []string{"XMT", "SMT"}, []string{"S53"}},
+		{"john", []string{"JN", "AN"}, []string{"J5"}},
+		{"michael", []string{"MKL", "MXL"}, []string{"M24"}},
+		{"knight", []string{"NT"}, []string{"K523"}},
+		{"laugh", []string{"LF"}, []string{"L2"}},
+		{"ashcraft", []string{"AXKR"}, []string{"A261"}},
+		{"tymczak", []string{"TMSK", "TMXK"}, []string{"T522"}},
+		{"42", nil, nil},
+	}
+	for _, tt := range tests {
+		if got := (DoubleMetaphoneEncoder{}).Encode(tt.word); !slices.Equal(got, tt.metaphone) {
+			t.Errorf("DoubleMetaphoneEncoder.Encode(%q) = %q, want %q", tt.word, got, tt.metaphone)
+		}
+		if got := (SoundexEncoder{}).Encode(tt.word); !slices.Equal(got, tt.soundex) {
+			t.Errorf("SoundexEncoder.Encode(%q) = %q, want %q", tt.word, got, tt.soundex)
+		}
+	}
+}
+
+func TestPhoneticFilter(t *testing.T) {
+	analyzer := NewDefaultAnalyzer(nil).WithTokenFilters(PhoneticFilter(SoundexEncoder{}))
+	got := analyzer.Tokenize(&valueItem{value: "John Smith"})
+	want := []string{phoneticNamespace + "J5 S53", phoneticNamespace + "S53", "john smith", "smith"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Tokenize() = %q, want %q", got, want)
+	}
+	if got := analyzer.tokenVariation("John Smith", phoneticNamespace+"S53"); got != TokenVariationPhonetic {
+		t.Errorf("tokenVariation() = %v, want %v", got, TokenVariationPhonetic)
+	}
+}
+
+func TestPrefixSearchPhonetic(t *testing.T) {
+	items := []*ExampleItem{
+		{Text: "John Smith", Rank: 10},
+		{Text: "Jon Smyth", Rank: 4},
+		{Text: "Joan Smithers", Rank: 2},
+		{Text: "Jane Doe", Rank: 1},
+	}
+	analyzer := NewDefaultAnalyzer(nil).WithTokenFilters(PhoneticFilter(nil))
+	index, _ := New[*ExampleItem](WithTokenizer(analyzer)).IndexItems(items)
+
+	for query, want := range map[string][]string{
+		// The literal match of "Jon Smyth" scores 4, above the phonetic match of "John Smith" at 10 * 0.3 = 3
+		"jon smyth": {"Jon Smyth", "John Smith", "Joan Smithers"},
+		"smith":     {"John Smith", "Joan Smithers", "Jon Smyth"},
+		"doe":       {"Jane Doe"},
+	} {
+		results, _ := index.PrefixSearch(query, 0, nil, WithPhonetic[*ExampleItem](0.7))
+		got := resultTexts(results)
+		if !slices.Equal(got, want) {
+			t.Errorf("PrefixSearch(%q) = %v, want %v", query, got, want)
+		}
+	}
+
+	// A penalty of 1 ranks phonetic matches below literal matches with a positive rank
+	results, _ := index.PrefixSearch("jane", 0, nil, WithPhonetic[*ExampleItem](1))
+	got := resultTexts(results)
+	if want := []string{"Jane Doe", "John Smith", "Jon Smyth", "Joan Smithers"}; !slices.Equal(got, want) {
+		t.Errorf("PrefixSearch() = %v, want %v", got, want)
+	}
+
+	// Phonetic matches are only searched with the search option
+	results, _ = index.PrefixSearch("jon smyth", 0, nil)
+	if len(results) != 1 || results[0].Text != "Jon Smyth" {
+		t.Errorf("PrefixSearch() without WithPhonetic = %v", results)
+	}
+
+	explanation := index.Explain("jon smyth", 0, nil, WithPhonetic[*ExampleItem](0.7))
+	if r := explanation.Results[1]; r.Variation != TokenVariationPhonetic || r.Alternative != phoneticNamespace+"JN SM0" {
+		t.Errorf("Unexpected explanation: %+v", r)
+	}
+
+	for _, suggestion := range index.Suggest("smi", 0) {
+		if isNamespacedToken(suggestion.Text) {
+			t.Errorf("Suggested phonetic token %q", suggestion.Text)
+		}
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"container/heap"
 	"log/slog"
 	"math"
+	"slices"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/regalias/lodestar/internal/utils"
 )
@@ -13,121 +16,588 @@ import (
 type result[T IndexableItem] struct {
 	Value T
 	Rank  int
+
+	// Weight of the matched value, and the score combining it with the rank that results are ordered by
+	Weight float64
+	Score  float64
+
+	// Dynamic boost from the rank overlay and learned selections, included in the score
+	Boost float64
+
+	Token string
+
+	// Form of the query that matched the token, if it was not the normalized query itself, e.g. a synonym
+	Query string
+
+	// Insertion sequence number of the item, used as the final tie breaker
+	seq uint64
 }
 
-// resultHeap implements heap.Interface for sorting search results by rank.
-type resultHeap[T IndexableItem] []result[T]
+// resultHeap implements heap.Interface for sorting search results by score.
+// The worst result according to the tie breaker is at the top of the heap.
+type resultHeap[T IndexableItem] struct {
+	results    []result[T]
+	tieBreaker TieBreaker
+}
 
-func (h resultHeap[T]) Len() int           { return len(h) }
-func (h resultHeap[T]) Less(i, j int) bool { return h[i].Rank < h[j].Rank }
-func (h resultHeap[T]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h resultHeap[T]) Len() int { return len(h.results) }
+func (h resultHeap[T]) Less(i, j int) bool {
+	return compareResults(h.tieBreaker, h.results[i], h.results[j]) > 0
+}
+func (h resultHeap[T]) Swap(i, j int) { h.results[i], h.results[j] = h.results[j], h.results[i] }
 func (h *resultHeap[T]) Push(x any) {
-	*h = append(*h, x.(result[T]))
+	h.results = append(h.results, x.(result[T]))
 }
 func (h *resultHeap[T]) Pop() any {
-	old := *h
+	old := h.results
 	n := len(old)
 	item := old[n-1]
-	*h = old[0 : n-1]
+	h.results = old[0 : n-1]
 	return item
 }
 
+// searchBuffers holds the reusable state for a single prefix search.
+type searchBuffers[T IndexableItem] struct {
+	// Best score offered for each seen item, or +Inf if it was filtered out
+	seen    map[any]float64
+	minHeap resultHeap[T]
+
+	// Score of the live heap entry of each item, only used for weighted indexes where an item may be
+	// offered again with a higher score, leaving its previous entry stale
+	live map[any]float64
+
+	// Optional trace of aggregation decisions, only set when explaining a query
+	trace *searchTrace[T]
+}
+
+// reset clears the buffers for reuse by another search.
+func (b *searchBuffers[T]) reset() {
+	clear(b.seen)
+	clear(b.live)
+	clear(b.minHeap.results)
+	b.minHeap.results = b.minHeap.results[:0]
+}
+
+// getSearchBuffers returns a set of search buffers from the index pool.
+func (idx *Index[T]) getSearchBuffers() *searchBuffers[T] {
+	if buffers, ok := idx.buffers.Get().(*searchBuffers[T]); ok {
+		return buffers
+	}
+	return newSearchBuffers[T]()
+}
+
+// newSearchBuffers allocates a new set of search buffers.
+func newSearchBuffers[T IndexableItem]() *searchBuffers[T] {
+	return &searchBuffers[T]{
+		seen: make(map[any]float64),
+		live: make(map[any]float64),
+	}
+}
+
+// putSearchBuffers resets and returns the search buffers to the index pool.
+func (idx *Index[T]) putSearchBuffers(buffers *searchBuffers[T]) {
+	buffers.reset()
+	idx.buffers.Put(buffers)
+}
+
 // Search performs a prefix search and returns results sorted by rank (descending) up to the specified limit.
-// The prefix is normalized before searching.
+// For items implementing WeightedIndexableItem, the rank is multiplied by the weight of the matched value,
+// or divided by it for negative ranks, so that lower weights always rank lower.
+// If the index has a RankOverlay or learns from selections, the boosts of each item are added to its score.
+// If the tokenizer searches alternative forms of queries, or the index has Synonyms, the alternatives
+// are searched as well, and their matches are merged with the literal matches, scored with their penalty.
+// The prefix is normalized before searching. If the tokenizer implements QueryTokenizer, every form of
+// the query is searched and their matches are merged.
 // If a filter function is provided, it will be applied to each item before including it in the results.
 // The results are deduplicated based on the item's GetID() value.
-func (idx *Index[T]) PrefixSearch(prefix string, limit int, filterFn ResultFilterFn[T]) ([]T, QueryTimingInfo) {
+// Search options can be provided to change how results are aggregated, e.g. WithGroupBy.
+func (idx *Index[T]) PrefixSearch(prefix string, limit int, filterFn ResultFilterFn[T], opts ...SearchOption[T]) ([]T, QueryTimingInfo) {
+	buffers := idx.getSearchBuffers()
+	defer idx.putSearchBuffers(buffers)
+	return idx.prefixSearch(prefix, limit, filterFn, newSearchOptions(opts), buffers)
+}
+
+// prefixSearch performs a prefix search using the provided buffers for deduplication and aggregation.
+func (idx *Index[T]) prefixSearch(prefix string, limit int, filterFn ResultFilterFn[T], options *searchOptions[T], buffers *searchBuffers[T]) ([]T, QueryTimingInfo) {
 
 	t0 := time.Now()
-	prefix = idx.tokenizer.NormalizeString(prefix)
+	queries := idx.normalizeQuery(prefix)
 
-	if prefix == "" {
+	if len(queries) == 0 {
 		return nil, QueryTimingInfo{}
 	}
 
+	agg := idx.newAggregation(queries, limit, filterFn, options, buffers)
+
+	root := idx.index.Root()
+
+	t1 := time.Now()
+
+	var seekTime time.Duration
+	for _, form := range agg.forms {
+		iter := root.Iterator()
+
+		t := time.Now()
+		iter.SeekPrefix([]byte(form.prefix))
+		seekTime += time.Since(t)
+
+		agg.form = form
+		for token, postings, ok := iter.Next(); ok; token, postings, ok = iter.Next() {
+			agg.add(string(token), postings)
+		}
+	}
+
+	t2 := time.Now()
+
+	results := agg.results()
+
+	t3 := time.Now()
+
+	return results, QueryTimingInfo{
+		InitTime:        t1.Sub(t0),
+		SeekTime:        seekTime,
+		AggregationTime: t2.Sub(t1) - seekTime,
+		TotalTime:       t3.Sub(t0),
+	}
+}
+
+// queryForm is a form of the normalized query that is searched, e.g. the query itself or a synonym.
+type queryForm struct {
+	prefix string
+
+	// Factor applied to the scores of the matches of the form
+	factor float64
+
+	// Optional check that items matching the form also match the query, if the form is less specific
+	match func(item IndexableItem) bool
+}
+
+// queryFormer is implemented by tokenizers that search alternative forms of normalized queries,
+// e.g. a form with accents stripped.
+type queryFormer interface {
+	queryForms(normalized string) []queryForm
+}
+
+// tokenizeQuery returns the normalized forms of the query from the tokenizer, or only the normalized
+// query if the tokenizer does not implement QueryTokenizer.
+func tokenizeQuery(tokenizer Tokenizer, query string) []string {
+	if queryTokenizer, ok := tokenizer.(QueryTokenizer); ok {
+		return queryTokenizer.TokenizeQuery(query)
+	}
+	return []string{tokenizer.NormalizeString(query)}
+}
+
+// tokenizerQueryForms returns the alternative forms of the normalized query from the tokenizer, if it
+// implements queryFormer, so that wrapping tokenizers keep the forms of the tokenizers they wrap.
+func tokenizerQueryForms(tokenizer Tokenizer, normalized string) []queryForm {
+	if former, ok := tokenizer.(queryFormer); ok {
+		return former.queryForms(normalized)
+	}
+	return nil
+}
+
+// normalizeQuery returns the normalized forms of the query, starting with the normalized query itself,
+// or none if it normalizes to an empty string.
+func (idx *Index[T]) normalizeQuery(query string) []string {
+	queries := tokenizeQuery(idx.tokenizer, query)
+	if len(queries) == 0 || queries[0] == "" {
+		return nil
+	}
+	return slices.DeleteFunc(queries, func(q string) bool { return q == "" })
+}
+
+// queryForms returns the forms of the normalized queries to search, starting with the queries themselves,
+// followed by the alternative forms of the tokenizer, the synonyms of the queries and, if requested,
+// their phonetic codes.
+func (idx *Index[T]) queryForms(queries []string, options *searchOptions[T]) []queryForm {
+	forms := make([]queryForm, 0, len(queries))
+	addForm := func(form queryForm) {
+		if form.prefix == "" || slices.ContainsFunc(forms, func(f queryForm) bool { return f.prefix == form.prefix }) {
+			return
+		}
+		forms = append(forms, form)
+	}
+
+	for _, query := range queries {
+		addForm(queryForm{prefix: query, factor: 1})
+	}
+	for _, query := range queries {
+		for _, form := range tokenizerQueryForms(idx.tokenizer, query) {
+			addForm(form)
+		}
+		if idx.synonyms != nil {
+			for _, alternative := range idx.synonyms.expand(query) {
+				addForm(queryForm{prefix: idx.tokenizer.NormalizeString(alternative), factor: 1 - idx.synonymPenalty})
+			}
+		}
+		if options.phonetic {
+			for _, code := range tokenizerPhoneticForms(idx.tokenizer, query) {
+				addForm(queryForm{prefix: code, factor: 1 - options.phoneticPenalty})
+			}
+		}
+	}
+	return forms
+}
+
+// aggregation holds the state of aggregating the posting lists of matching tokens into the top results.
+type aggregation[T IndexableItem] struct {
+	prefix      string
+	limit       int
+	resultLimit int
+	filterFn    ResultFilterFn[T]
+	options     *searchOptions[T]
+	tieBreaker  TieBreaker
+	buffers     *searchBuffers[T]
+
+	// Forms of the query to search, and the form whose matches are currently added
+	forms []queryForm
+	form  queryForm
+
+	// Whether an item may score differently under different tokens
+	weighted bool
+
+	// Min-heap to get top K
+	minHeap resultHeap[T]
+
+	// Collector used instead of the min-heap when collapsing on groups
+	grouped *groupCollector[T]
+
+	// Optional dynamic boosts, evaluated as of the start of the search
+	boosted  bool
+	overlay  *RankOverlay
+	learned  map[string]float64
+	keyOf    func(id any) string
+	maxBoost float64
+	now      time.Time
+}
+
+// newAggregation prepares an aggregation for the normalized forms of a query using the provided buffers.
+func (idx *Index[T]) newAggregation(queries []string, limit int, filterFn ResultFilterFn[T], options *searchOptions[T], buffers *searchBuffers[T]) *aggregation[T] {
 	// Default to no limit
 	if limit <= 0 {
 		limit = math.MaxInt
 	}
 
-	// Set for deduplication
-	seen := make(map[any]struct{}, limit)
+	prefix := queries[0]
+	agg := &aggregation[T]{
+		prefix:      prefix,
+		limit:       limit,
+		resultLimit: limit,
+		filterFn:    filterFn,
+		options:     options,
+		tieBreaker:  idx.tieBreaker,
+		buffers:     buffers,
+		minHeap:     buffers.minHeap,
+		forms:       idx.queryForms(queries, options),
+	}
+	agg.form = agg.forms[0]
 
-	// Min-heap to get top K
-	minHeap := resultHeap[T]{}
-	heap.Init(&minHeap)
+	// Items matching several forms score differently under each of them
+	agg.weighted = idx.weighted || len(agg.forms) > 1
 
-	iter := idx.index.Root().Iterator()
+	// Collect a larger pool of candidates to diversify the top results from
+	if options.diversityKey != nil {
+		agg.limit = diversityPoolLimit(limit)
+	}
 
-	t1 := time.Now()
+	if idx.overlay != nil {
+		agg.boosted = true
+		agg.overlay = idx.overlay
+		agg.maxBoost = idx.overlay.MaxBoost()
+		agg.now = idx.overlay.now()
+	}
+	if idx.feedback != nil {
+		learned, maxLearned, err := idx.feedback.boosts(prefix, idx.feedback.now())
+		if err != nil {
+			slog.Warn("Failed to load learned selection boosts", "query", prefix, "error", err)
+		}
+		if len(learned) > 0 {
+			agg.boosted = true
+			agg.learned = learned
+			agg.keyOf = idx.feedback.config.Key
+			agg.maxBoost += maxLearned
+		}
+	}
 
-	iter.SeekPrefix([]byte(prefix))
+	agg.minHeap.tieBreaker = idx.tieBreaker
+	heap.Init(&agg.minHeap)
 
-	t2 := time.Now()
+	// Collapse on groups during aggregation if requested. Otherwise, when diversifying, keep at most
+	// limit candidates per diversity key, so that the pool is not filled by the items of a single key
+	if options.groupKey != nil {
+		agg.grouped = newGroupCollector(agg.limit, options.groupKey, options.groupLimit, CutGroupLimit, idx.tieBreaker, buffers.trace)
+	} else if options.diversityKey != nil {
+		agg.grouped = newGroupCollector(agg.limit, options.diversityKey, limit, CutDiversity, idx.tieBreaker, buffers.trace)
+	}
+	return agg
+}
 
-	for token, items, ok := iter.Next(); ok; token, items, ok = iter.Next() {
-		tokenStr := string(token)
-		if len(items) == 0 {
-			slog.Warn("Got empty items for token", "token", tokenStr)
-			continue
+// add aggregates the posting list of a matching token.
+func (agg *aggregation[T]) add(token string, postings []posting[T]) {
+	if len(postings) == 0 {
+		slog.Warn("Got empty items for token", "token", token)
+		return
+	}
+
+	// Set for deduplication
+	seen := agg.buffers.seen
+	trace := agg.buffers.trace
+
+	for i, p := range postings {
+		item := p.item
+		id := item.GetID()
+		candidate := p.result(token)
+		if agg.form.prefix != agg.prefix {
+			candidate.Score = scaleScore(candidate.Score, agg.form.factor)
+			candidate.Query = agg.form.prefix
+		}
+		unboosted := candidate
+		if agg.boosted {
+			candidate.Boost = agg.boost(id)
+			candidate.Score += candidate.Boost
 		}
 
-		for _, item := range items {
+		// Skip duplicates, unless a weighted item scores higher under this token
+		if best, exists := seen[id]; exists && (!agg.weighted || candidate.Score <= best) {
+			trace.cut(item, token, CutDuplicate)
+			continue
+		}
 
-			// Skip duplicates
-			if _, exists := seen[item.GetID()]; exists {
-				continue
-			}
-			seen[item.GetID()] = struct{}{}
+		// Skip items that match a less specific form of the query but not the query itself
+		if agg.form.match != nil && !agg.form.match(item) {
+			trace.cut(item, token, CutFiltered)
+			continue
+		}
 
-			// Apply the filter function if provided
-			if filterFn != nil && !filterFn(prefix, tokenStr, item) {
-				continue
+		// Apply the filter function if provided
+		if agg.filterFn != nil && !agg.filterFn(agg.prefix, token, item) {
+			if _, exists := seen[id]; !exists {
+				seen[id] = math.Inf(1)
 			}
+			trace.cut(item, token, CutFiltered)
+			continue
+		}
+		seen[id] = candidate.Score
 
-			if len(minHeap) < limit {
-				// Not enough items, just add it
-				heap.Push(&minHeap, result[T]{Value: item, Rank: item.GetRank()})
-			} else if item.GetRank() > minHeap[0].Rank {
-				// The current item has a higher rank than the lowest in the heap, replace it
-				heap.Pop(&minHeap)
-				heap.Push(&minHeap, result[T]{Value: item, Rank: item.GetRank()})
-			} else {
-				// Current item is not better than the worst item in the heap, stop here
-				// The rest of the items will have lower rank since they are already sorted by descending rank
-				break
-			}
+		var stop bool
+		if agg.grouped != nil {
+			stop = agg.grouped.offer(candidate)
+		} else {
+			stop = agg.offer(candidate)
+		}
+		if stop && agg.boosted && !agg.exhausted(unboosted) {
+			// A later item may still be boosted above the worst result
+			trace.cut(item, token, CutLimit)
+			continue
 		}
+		if stop {
+			// Current item is not better than the worst result, stop here
+			// The rest of the items will rank lower since they are already sorted by descending score and tie breaker
+			trace.earlyExit(token, len(postings)-i)
+			return
+		}
+	}
+}
+
+// offer adds the candidate to the top results if it ranks high enough.
+// It returns true if neither the candidate nor any lower ranked candidate can be added.
+func (agg *aggregation[T]) offer(candidate result[T]) (stop bool) {
+	trace := agg.buffers.trace
+	live := agg.buffers.live
+	size := agg.minHeap.Len()
+	if agg.weighted {
+		// A previous entry of the item is replaced, and stale entries are skipped
+		delete(live, candidate.Value.GetID())
+		agg.pruneStale()
+		size = len(live)
 	}
 
-	t3 := time.Now()
+	if size < agg.limit {
+		// Not enough items, just add it
+		heap.Push(&agg.minHeap, candidate)
+	} else if compareResults(agg.tieBreaker, candidate, agg.minHeap.results[0]) < 0 {
+		// The current item ranks higher than the lowest in the heap, replace it
+		evicted := heap.Pop(&agg.minHeap).(result[T])
+		heap.Push(&agg.minHeap, candidate)
+		delete(live, evicted.Value.GetID())
+		trace.evict(evicted.Value, CutLimit)
+	} else {
+		return true
+	}
 
-	// Get the results from min-heap in ascending order of rank
-	results := make([]T, 0, len(minHeap))
-	for minHeap.Len() > 0 {
-		result := heap.Pop(&minHeap).(result[T])
-		results = append(results, result.Value)
+	if agg.weighted {
+		live[candidate.Value.GetID()] = candidate.Score
 	}
+	trace.accept(candidate)
+	return false
+}
 
-	// Reverse the results to get them in descending order of rank
-	utils.ReverseSliceInPlace(results)
-	t4 := time.Now()
+// boost returns the dynamic boost of an item.
+func (agg *aggregation[T]) boost(id any) float64 {
+	var boost float64
+	if agg.learned != nil {
+		boost = agg.learned[agg.keyOf(id)]
+	}
+	if agg.overlay != nil {
+		boost += agg.overlay.boostAt(id, agg.now)
+	}
+	return boost
+}
 
-	return results, QueryTimingInfo{
-		InitTime:        t1.Sub(t0),
-		SeekTime:        t2.Sub(t1),
-		AggregationTime: t3.Sub(t2),
-		TotalTime:       t4.Sub(t0),
+// exhausted returns true if no posting ordered after the unboosted candidate can beat the worst result,
+// even with the maximum boost.
+func (agg *aggregation[T]) exhausted(unboosted result[T]) bool {
+	worst := agg.minHeap.results[0]
+	if agg.grouped != nil {
+		worst = agg.grouped.minHeap.results[0]
 	}
+	unboosted.Score += agg.maxBoost
+	return compareResults(agg.tieBreaker, unboosted, worst) >= 0
+}
+
+// isStale returns true if the heap entry was replaced by a higher scoring entry of the same item.
+func (agg *aggregation[T]) isStale(r result[T]) bool {
+	score, found := agg.buffers.live[r.Value.GetID()]
+	return !found || score != r.Score
+}
+
+// pruneStale pops stale entries from the top of the heap, so that the top is the worst live result.
+func (agg *aggregation[T]) pruneStale() {
+	for agg.minHeap.Len() > 0 && agg.isStale(agg.minHeap.results[0]) {
+		heap.Pop(&agg.minHeap)
+	}
+}
+
+// results returns the aggregated results in order and hands the buffers back for reuse.
+func (agg *aggregation[T]) results() []T {
+	var results []result[T]
+	if agg.grouped != nil {
+		results = agg.grouped.results()
+	} else {
+		// Get the results from min-heap in ascending order of score
+		results = make([]result[T], 0, agg.minHeap.Len())
+		for agg.minHeap.Len() > 0 {
+			result := heap.Pop(&agg.minHeap).(result[T])
+			if agg.weighted && agg.isStale(result) {
+				continue
+			}
+			results = append(results, result)
+		}
+
+		// Reverse the results to get them in descending order of score
+		utils.ReverseSliceInPlace(results)
+	}
+	if agg.options.diversityKey != nil {
+		// Diversify from the pool in rank order, as groups keep their items together
+		slices.SortStableFunc(results, func(a, b result[T]) int {
+			return compareResults(agg.tieBreaker, a, b)
+		})
+		results = diversify(results, agg.resultLimit, agg.options.diversityKey, agg.options.diversityLambda, agg.buffers.trace)
+	}
+
+	// Hand the grown heap back to the buffers for reuse
+	agg.buffers.minHeap = agg.minHeap
+
+	items := make([]T, len(results))
+	for i, r := range results {
+		items[i] = r.Value
+	}
+	return items
 }
 
 // Get retrieves an item by exact match
 // The value is normalized before searching the indexes
+// If the tokenizer implements QueryTokenizer, every form of the value is looked up, with the items
+// of the first form returned first.
 func (idx *Index[T]) Get(value string) (item []T, found bool) {
-	value = idx.tokenizer.NormalizeString(value)
-	if value == "" {
+	queries := idx.normalizeQuery(value)
+	if len(queries) == 0 {
 		return item, false
 	}
 	root := idx.index.Root()
-	return root.Get([]byte(value))
+	postings, found := root.Get([]byte(queries[0]))
+	if len(queries) == 1 {
+		return postingItems(postings), found
+	}
+
+	// Merge the items of the other forms after those of the query, skipping duplicates
+	item = postingItems(postings)
+	seen := make(map[any]struct{}, len(item))
+	for _, i := range item {
+		seen[i.GetID()] = struct{}{}
+	}
+	for _, query := range queries[1:] {
+		postings, ok := root.Get([]byte(query))
+		if !ok {
+			continue
+		}
+		found = true
+		for _, p := range postings {
+			if _, dup := seen[p.item.GetID()]; !dup {
+				seen[p.item.GetID()] = struct{}{}
+				item = append(item, p.item)
+			}
+		}
+	}
+	return item, found
+}
+
+// PrefixMatch represents an indexed token that is a prefix of a lookup input.
+type PrefixMatch[T IndexableItem] struct {
+	Token string
+	Items []T
+}
+
+// LongestPrefix retrieves the longest indexed token that is a prefix of the input ending at a word
+// boundary, e.g. resolving "/deploy prod now" to the items indexed under "/deploy", but not "/deployment".
+// The input is normalized before searching the indexes
+func (idx *Index[T]) LongestPrefix(input string) (token string, items []T, found bool) {
+	input = idx.tokenizer.NormalizeString(input)
+	if input == "" {
+		return "", nil, false
+	}
+	var longest []posting[T]
+	idx.index.Root().WalkPath([]byte(input), func(key []byte, postings []posting[T]) bool {
+		if isWordBoundary(input, len(key)) {
+			token, longest, found = string(key), postings, true
+		}
+		return false
+	})
+	if !found {
+		return "", nil, false
+	}
+	return token, postingItems(longest), true
+}
+
+// PrefixMatches retrieves every indexed token that is a prefix of the input ending at a word boundary,
+// ordered from shortest to longest.
+// The input is normalized before searching the indexes
+func (idx *Index[T]) PrefixMatches(input string) []PrefixMatch[T] {
+	input = idx.tokenizer.NormalizeString(input)
+	if input == "" {
+		return nil
+	}
+	var matches []PrefixMatch[T]
+	idx.index.Root().WalkPath([]byte(input), func(key []byte, postings []posting[T]) bool {
+		if isWordBoundary(input, len(key)) {
+			matches = append(matches, PrefixMatch[T]{Token: string(key), Items: postingItems(postings)})
+		}
+		return false
+	})
+	return matches
+}
+
+// isWordBoundary returns true if the input ends at the byte offset, or if a separator, i.e. a character
+// other than a letter or digit, precedes or follows it.
+func isWordBoundary(input string, offset int) bool {
+	if offset >= len(input) {
+		return true
+	}
+	before, _ := utf8.DecodeLastRuneInString(input[:offset])
+	after, _ := utf8.DecodeRuneInString(input[offset:])
+	return !isWordRune(before) || !isWordRune(after)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
 }
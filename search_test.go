@@ -3,7 +3,11 @@ package lodestar
 import (
 	"fmt"
 	"math/rand"
+	"slices"
+	"strings"
 	"testing"
+
+	"golang.org/x/text/language"
 )
 
 const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
@@ -149,3 +153,127 @@ func BenchmarkSearchMixedCase(b *testing.B) {
 		index.PrefixSearch(toQuery[i%3], 100, nil)
 	}
 }
+
+func TestLongestPrefix(t *testing.T) {
+	index := setupIndexWithItems([]*ExampleItem{
+		{Text: "/deploy", Rank: 10},
+		{Text: "/deploy prod", Rank: 5},
+		{Text: "/dev", Rank: 3},
+	})
+
+	token, items, found := index.LongestPrefix("/Deploy prod now")
+	if !found || token != "/deploy prod" || len(items) != 1 || items[0].Text != "/deploy prod" {
+		t.Errorf("LongestPrefix() got %q %v %v", token, items, found)
+	}
+
+	if _, _, found := index.LongestPrefix("/status"); found {
+		t.Errorf("Expected no match for '/status'")
+	}
+
+	// Tokens only match up to a word boundary of the input
+	if token, _, found := index.LongestPrefix("/deployment"); found {
+		t.Errorf("LongestPrefix('/deployment') got %q, want no match", token)
+	}
+	if token, _, found := index.LongestPrefix("/deploy-prod"); !found || token != "/deploy" {
+		t.Errorf("LongestPrefix('/deploy-prod') got %q %v", token, found)
+	}
+	if matches := index.PrefixMatches("/deployment"); len(matches) != 0 {
+		t.Errorf("PrefixMatches('/deployment') got %+v, want none", matches)
+	}
+
+	matches := index.PrefixMatches("/deploy prod now")
+	if len(matches) != 2 || matches[0].Token != "/deploy" || matches[1].Token != "/deploy prod" {
+		t.Errorf("PrefixMatches() got %+v", matches)
+	}
+}
+
+func TestPrefixSearchBatch(t *testing.T) {
+	queries := []string{"app", "fruit", "zzz", "", "approach"}
+
+	for _, concurrency := range []int{1, 4} {
+		index, _ := New[*ExampleItem](WithBatchConcurrency(concurrency)).IndexItems(testItems)
+		batch := index.PrefixSearchBatch(queries, 2, nil)
+		if len(batch) != len(queries) {
+			t.Fatalf("Expected %d batch results, got %d", len(queries), len(batch))
+		}
+
+		for i, query := range queries {
+			want, _ := index.PrefixSearch(query, 2, nil)
+			got := batch[i]
+			if got.Query != query {
+				t.Errorf("Batch result %d has query %q, want %q", i, got.Query, query)
+			}
+			if len(got.Results) != len(want) {
+				t.Errorf("Batch results for %q got %d items, want %d", query, len(got.Results), len(want))
+				continue
+			}
+			for j := range want {
+				if got.Results[j] != want[j] {
+					t.Errorf("Batch results for %q differ at %d: got %v, want %v", query, j, got.Results[j], want[j])
+				}
+			}
+		}
+	}
+}
+
+// alternativesTokenizer searches each alternative of queries separated by "|".
+type alternativesTokenizer struct {
+	DefaultTokenizer
+}
+
+func (t *alternativesTokenizer) TokenizeQuery(query string) []string {
+	queries := []string{t.NormalizeString(query)}
+	for _, alternative := range strings.Split(query, "|") {
+		queries = append(queries, t.NormalizeString(alternative))
+	}
+	return queries
+}
+
+func TestQueryTokenizer(t *testing.T) {
+	items := []*ExampleItem{
+		{Text: "apple", Rank: 10},
+		{Text: "banana", Rank: 20},
+		{Text: "cherry", Rank: 5},
+	}
+	index, _ := New[*ExampleItem](WithTokenizer(&alternativesTokenizer{})).IndexItems(items)
+
+	results, _ := index.PrefixSearch("app|cher|ban", 0, nil)
+	got := resultTexts(results)
+	if want := []string{"banana", "apple", "cherry"}; !slices.Equal(got, want) {
+		t.Errorf("PrefixSearch() = %v, want %v", got, want)
+	}
+
+	if items, found := index.Get("cherry|apple|cherry"); !found || len(items) != 2 || items[0].Text != "cherry" || items[1].Text != "apple" {
+		t.Errorf("Get() = %v, %v", items, found)
+	}
+	if _, found := index.Get("app|cher"); found {
+		t.Errorf("Get() found partial values")
+	}
+
+	// The DefaultTokenizer searches queries without hyphens and brackets as well, also when wrapped
+	for _, tokenizer := range []Tokenizer{
+		&DefaultTokenizer{},
+		NewFoldingTokenizer(nil, language.Und),
+		NewStemmingTokenizer(nil, nil),
+		NewCJKTokenizer(nil),
+		NewNGramTokenizer(nil, 2, 3, NGramEdge),
+	} {
+		index, _ := New[*ExampleItem](WithTokenizer(tokenizer)).IndexItems([]*ExampleItem{
+			{Text: "it just works", Rank: 2},
+			{Text: "hello world", Rank: 1},
+		})
+		for query, want := range map[string]string{
+			"just-works":    "it just works",
+			"hello (world)": "hello world",
+			"[hello]-wor":   "hello world",
+		} {
+			results, _ := index.PrefixSearch(query, 0, nil)
+			if len(results) != 1 || results[0].Text != want {
+				t.Errorf("PrefixSearch(%q) with %T = %v, want %q", query, tokenizer, results, want)
+			}
+		}
+		if items, found := index.Get("hello-world"); !found || items[0].Text != "hello world" {
+			t.Errorf("Get() with %T = %v, %v", tokenizer, items, found)
+		}
+	}
+}
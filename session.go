@@ -0,0 +1,148 @@
+package lodestar
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultSessionCandidateLimit is the default maximum number of candidate tokens a Session remembers.
+const DefaultSessionCandidateLimit = 4096
+
+// sessionCandidate is a token and its posting list that matched the previous query of a session.
+type sessionCandidate[T IndexableItem] struct {
+	token    string
+	postings []posting[T]
+}
+
+// Session performs incremental prefix searches against a single snapshot of an index, e.g. for
+// as-you-type queries. It remembers the tokens matching the previous query, so that a query that
+// extends the previous one narrows those candidates instead of seeking from the root again.
+// Edits other than appending, such as backspace, fall back to a full search.
+//
+// A Session is not safe for concurrent use.
+type Session[T IndexableItem] struct {
+	idx           *Index[T]
+	maxCandidates int
+	buffers       *searchBuffers[T]
+
+	// Normalized previous query and its matching tokens, nil if there were too many to remember
+	prefix     string
+	candidates []sessionCandidate[T]
+}
+
+// NewSession creates a new incremental search Session bound to the current snapshot of the index.
+// Up to maxCandidates matching tokens are remembered between queries, if maxCandidates <= 0 it
+// defaults to DefaultSessionCandidateLimit.
+func (idx *Index[T]) NewSession(maxCandidates int) *Session[T] {
+	if maxCandidates <= 0 {
+		maxCandidates = DefaultSessionCandidateLimit
+	}
+	return &Session[T]{
+		idx:           idx,
+		maxCandidates: maxCandidates,
+		buffers:       newSearchBuffers[T](),
+	}
+}
+
+// PrefixSearch performs a prefix search like Index.PrefixSearch.
+// If the normalized prefix extends the previous query of the session, and the tokens matching the
+// previous query were remembered, the results are aggregated from those tokens instead.
+// The returned timing info reports whether the search was narrowed and how many candidates were reused.
+func (s *Session[T]) PrefixSearch(prefix string, limit int, filterFn ResultFilterFn[T], opts ...SearchOption[T]) ([]T, QueryTimingInfo) {
+	defer s.buffers.reset()
+
+	t0 := time.Now()
+	queries := s.idx.normalizeQuery(prefix)
+
+	if len(queries) == 0 {
+		s.prefix, s.candidates = "", nil
+		return nil, QueryTimingInfo{}
+	}
+	prefix = queries[0]
+
+	agg := s.idx.newAggregation(queries, limit, filterFn, newSearchOptions(opts), s.buffers)
+
+	// Queries with several forms, e.g. synonyms, are searched in full and not remembered
+	single := len(agg.forms) == 1
+	narrow := single && s.candidates != nil && strings.HasPrefix(prefix, s.prefix)
+	reused := len(s.candidates)
+
+	var candidates []sessionCandidate[T]
+	var t1, t2, t3 time.Time
+	if narrow {
+		t1 = time.Now()
+
+		// Narrow the remembered candidates in place, as the previous query is no longer needed
+		candidates = s.candidates[:0]
+		for _, c := range s.candidates {
+			if strings.HasPrefix(c.token, prefix) {
+				candidates = append(candidates, c)
+			}
+		}
+		clear(s.candidates[len(candidates):])
+
+		t2 = time.Now()
+
+		for _, c := range candidates {
+			agg.add(c.token, c.postings)
+		}
+	} else {
+		candidates = make([]sessionCandidate[T], 0)
+		overflow := !single
+
+		root := s.idx.index.Root()
+
+		t1 = time.Now()
+
+		var seekTime time.Duration
+		for _, form := range agg.forms {
+			iter := root.Iterator()
+
+			t := time.Now()
+			iter.SeekPrefix([]byte(form.prefix))
+			seekTime += time.Since(t)
+
+			agg.form = form
+			for token, postings, ok := iter.Next(); ok; token, postings, ok = iter.Next() {
+				tokenStr := string(token)
+				agg.add(tokenStr, postings)
+
+				// Remember the matching tokens unless there are too many
+				if overflow {
+					continue
+				}
+				if len(candidates) == s.maxCandidates {
+					overflow = true
+					continue
+				}
+				candidates = append(candidates, sessionCandidate[T]{token: tokenStr, postings: postings})
+			}
+		}
+		if overflow {
+			candidates = nil
+		}
+
+		t2 = t1.Add(seekTime)
+	}
+
+	t3 = time.Now()
+
+	results := agg.results()
+
+	t4 := time.Now()
+
+	s.prefix = prefix
+	s.candidates = candidates
+
+	timing := QueryTimingInfo{
+		InitTime:        t1.Sub(t0),
+		SeekTime:        t2.Sub(t1),
+		AggregationTime: t3.Sub(t2),
+		TotalTime:       t4.Sub(t0),
+		Narrowed:        narrow,
+	}
+	if narrow {
+		timing.ReusedCandidates = reused
+	}
+	return results, timing
+}
@@ -0,0 +1,43 @@
+package lodestar
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSessionPrefixSearch(t *testing.T) {
+	index := setupIndexWithItems(testItems)
+	session := index.NewSession(0)
+
+	for _, step := range []struct {
+		query    string
+		narrowed bool
+	}{
+		{query: "a", narrowed: false},
+		{query: "ap", narrowed: true},
+		{query: "app", narrowed: true},
+		{query: "appl", narrowed: true},
+		{query: "app", narrowed: false},
+		{query: "apply", narrowed: true},
+		{query: "b", narrowed: false},
+	} {
+		got, timing := session.PrefixSearch(step.query, 3, nil)
+		want, _ := index.PrefixSearch(step.query, 3, nil)
+		if !slices.Equal(got, want) {
+			t.Errorf("Session search for %q got %v, want %v", step.query, got, want)
+		}
+		if timing.Narrowed != step.narrowed {
+			t.Errorf("Session search for %q narrowed = %v, want %v", step.query, timing.Narrowed, step.narrowed)
+		}
+		if step.narrowed && timing.ReusedCandidates == 0 {
+			t.Errorf("Session search for %q should report reused candidates", step.query)
+		}
+	}
+
+	// Too many candidates to remember falls back to full searches
+	session = index.NewSession(1)
+	session.PrefixSearch("a", 3, nil)
+	if _, timing := session.PrefixSearch("ap", 3, nil); timing.Narrowed {
+		t.Errorf("Session search should not narrow when candidates overflowed")
+	}
+}
@@ -0,0 +1,134 @@
+package lodestar
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/regalias/lodestar/internal/utils"
+)
+
+// Stemmer reduces lowercase words to their stems, e.g. "running" to "run".
+type Stemmer interface {
+	Stem(word string) string
+}
+
+// EnglishStemmer is a Stemmer implementing the Porter2 (Snowball English) algorithm.
+type EnglishStemmer struct{}
+
+// Stem returns the Porter2 stem of a lowercase English word.
+func (EnglishStemmer) Stem(word string) string {
+	return utils.StemEnglish(word)
+}
+
+// StemmingTokenizer is a token filter that wraps a Tokenizer, indexing the tokens of the stemmed
+// values alongside the surface tokens, so that "running shoes" and "run shoe" find each other
+// while prefixes of partially typed words still match the surface tokens.
+// Queries are normalized by the wrapped tokenizer and then stemmed.
+type StemmingTokenizer struct {
+	tokenizerWrapper
+	Stemmer Stemmer
+}
+
+// NewStemmingTokenizer creates a StemmingTokenizer wrapping the tokenizer.
+// If tokenizer is nil, the DefaultTokenizer is used, and if stemmer is nil, the EnglishStemmer is used.
+func NewStemmingTokenizer(tokenizer Tokenizer, stemmer Stemmer) *StemmingTokenizer {
+	if tokenizer == nil {
+		tokenizer = &DefaultTokenizer{}
+	}
+	if stemmer == nil {
+		stemmer = EnglishStemmer{}
+	}
+	return &StemmingTokenizer{tokenizerWrapper: tokenizerWrapper{Tokenizer: tokenizer}, Stemmer: stemmer}
+}
+
+func (t *StemmingTokenizer) Tokenize(item IndexableItem) []string {
+	tokens := t.Tokenizer.Tokenize(item)
+	for _, value := range item.GetValuesForIndexing() {
+		if stemmedTokens := t.stemmedTokens(value); stemmedTokens != nil {
+			tokens = mergeUniqueTokens(tokens, stemmedTokens)
+		}
+	}
+	return tokens
+}
+
+// stemmedTokens returns the tokens of the stemmed value, or nil if stemming does not change the value.
+func (t *StemmingTokenizer) stemmedTokens(value string) []string {
+	normalized := t.Tokenizer.NormalizeString(value)
+	stemmed := stemWords(t.Stemmer, normalized)
+	if stemmed == normalized {
+		return nil
+	}
+	return t.Tokenizer.Tokenize(&valueItem{value: stemmed})
+}
+
+// NormalizeString normalizes the value with the wrapped tokenizer and stems each of its words.
+func (t *StemmingTokenizer) NormalizeString(value string) string {
+	return stemWords(t.Stemmer, t.Tokenizer.NormalizeString(value))
+}
+
+// TokenizeQuery returns the normalized forms of the query from the wrapped tokenizer, with their words
+// stemmed.
+func (t *StemmingTokenizer) TokenizeQuery(query string) []string {
+	var queries []string
+	for _, normalized := range tokenizeQuery(t.Tokenizer, query) {
+		if stemmed := stemWords(t.Stemmer, normalized); !slices.Contains(queries, stemmed) {
+			queries = append(queries, stemmed)
+		}
+	}
+	return queries
+}
+
+// stemWords stems every word of lowercase ASCII letters and apostrophes in the value,
+// leaving the other characters in place.
+func stemWords(stemmer Stemmer, value string) string {
+	var b strings.Builder
+	b.Grow(len(value))
+	start := -1
+	for i := 0; i <= len(value); i++ {
+		if i < len(value) && isStemmableByte(value[i]) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			b.WriteString(stemmer.Stem(value[start:i]))
+			start = -1
+		}
+		if i < len(value) {
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
+
+func isStemmableByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c == '\''
+}
+
+// Analyze returns the tokens of the wrapped tokenizer, followed by the tokens of the stemmed value.
+func (t *StemmingTokenizer) Analyze(value string) []TokenInfo {
+	tokens := t.tokenizerWrapper.Analyze(value)
+	normalized := t.Tokenizer.NormalizeString(value)
+	if stemmed := stemWords(t.Stemmer, normalized); stemmed != normalized {
+		for _, token := range analyzeValue(t.Tokenizer, stemmed) {
+			token.Variation = TokenVariationStemmed
+			tokens = append(tokens, token)
+		}
+	}
+	return uniqueTokenInfos(tokens)
+}
+
+// tokenVariation returns the rule of the wrapped tokenizer that produced the token, or
+// TokenVariationStemmed if it was produced from the stemmed value.
+func (t *StemmingTokenizer) tokenVariation(value string, token string) TokenVariation {
+	if variation := t.tokenizerWrapper.tokenVariation(value, token); variation != TokenVariationUnknown {
+		return variation
+	}
+	for _, stemmedToken := range t.stemmedTokens(value) {
+		if stemmedToken == token {
+			return TokenVariationStemmed
+		}
+	}
+	return TokenVariationUnknown
+}
@@ -0,0 +1,70 @@
+package lodestar
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEnglishStemmer(t *testing.T) {
+	tests := map[string]string{
+		"running":     "run",
+		"shoes":       "shoe",
+		"abilities":   "abil",
+		"happy":       "happi",
+		"consolingly": "consol",
+		"generously":  "generous",
+		"skies":       "sky",
+		"news":        "news",
+		"hopping":     "hop",
+		"agreed":      "agre",
+		"relational":  "relat",
+		"conspiracy":  "conspiraci",
+		"dying":       "die",
+		"succeeding":  "succeed",
+		"communism":   "communism",
+		"be":          "be",
+	}
+	stemmer := EnglishStemmer{}
+	for word, want := range tests {
+		if got := stemmer.Stem(word); got != want {
+			t.Errorf("Stem(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestStemmingTokenizer(t *testing.T) {
+	tr := NewStemmingTokenizer(nil, nil)
+
+	got := tr.Tokenize(&valueItem{value: "Running Shoes"})
+	want := []string{"run shoe", "running shoes", "shoe", "shoes"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Tokenize() = %v, want %v", got, want)
+	}
+
+	if got := tr.NormalizeString("Happy-Hour (Drinks)"); got != "happi-hour (drink)" {
+		t.Errorf("NormalizeString() = %q", got)
+	}
+	if got := tr.tokenVariation("Running Shoes", "run shoe"); got != TokenVariationStemmed {
+		t.Errorf("tokenVariation() = %v, want %v", got, TokenVariationStemmed)
+	}
+
+	items := []*ExampleItem{
+		{Text: "run shoe", Rank: 5},
+		{Text: "running shoes", Rank: 3},
+		{Text: "runway", Rank: 1},
+	}
+	index, _ := New[*ExampleItem](WithTokenizer(tr)).IndexItems(items)
+
+	for query, want := range map[string][]string{
+		"running shoes": {"run shoe", "running shoes"},
+		"run shoe":      {"run shoe", "running shoes"},
+		"runn":          {"running shoes"},
+		"run":           {"run shoe", "running shoes", "runway"},
+	} {
+		results, _ := index.PrefixSearch(query, 0, nil)
+		got := resultTexts(results)
+		if !slices.Equal(got, want) {
+			t.Errorf("PrefixSearch(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package lodestar
+
+import (
+	"strings"
+	"unicode"
+)
+
+// englishStopwords are the built-in English stopwords, a conservative list of articles,
+// conjunctions and prepositions that rarely carry meaning on their own.
+var englishStopwords = []string{
+	"a", "an", "and", "are", "as", "at", "be", "but", "by", "for", "if", "in", "into", "is", "it",
+	"no", "not", "of", "on", "or", "such", "that", "the", "their", "then", "there", "these",
+	"they", "this", "to", "was", "will", "with",
+}
+
+// builtinStopwords holds the built-in stopword lists by language code.
+var builtinStopwords = map[string][]string{
+	"en": englishStopwords,
+}
+
+// Stopwords is a set of lowercase words that are too common to start a token.
+type Stopwords map[string]struct{}
+
+// NewStopwords creates a set of stopwords from the words, which are lowercased.
+func NewStopwords(words ...string) Stopwords {
+	stopwords := make(Stopwords, len(words))
+	for _, word := range words {
+		stopwords[strings.ToLower(word)] = struct{}{}
+	}
+	return stopwords
+}
+
+// BuiltinStopwords returns the built-in stopwords of a language by its ISO 639-1 code, e.g. "en",
+// or nil if there are none for the language.
+func BuiltinStopwords(language string) Stopwords {
+	words, found := builtinStopwords[strings.ToLower(language)]
+	if !found {
+		return nil
+	}
+	return NewStopwords(words...)
+}
+
+// Contains returns true if the word is a stopword.
+func (s Stopwords) Contains(word string) bool {
+	_, found := s[word]
+	return found
+}
+
+// trimLeading removes leading stopwords from a normalized string, as long as another word follows.
+func (s Stopwords) trimLeading(value string) string {
+	if len(s) == 0 {
+		return value
+	}
+	for {
+		end := strings.IndexFunc(value, unicode.IsSpace)
+		if end < 0 || !s.Contains(value[:end]) {
+			return value
+		}
+		value = strings.TrimLeftFunc(value[end:], unicode.IsSpace)
+	}
+}
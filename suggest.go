@@ -0,0 +1,173 @@
+package lodestar
+
+import (
+	"cmp"
+	"slices"
+
+	iradix "github.com/hashicorp/go-immutable-radix/v2"
+
+	"github.com/regalias/lodestar/internal/utils"
+)
+
+const (
+	defaultSuggestMaxDistance   = 2
+	defaultSymSpellPrefixLength = 7
+)
+
+// Suggestion represents a vocabulary token that is a close match for a query.
+type Suggestion struct {
+	// Text is the suggested token as stored in the index
+	Text string
+
+	// Distance is the edit distance between the normalized query and the token
+	Distance int
+
+	// ItemCount is the number of items indexed under the token
+	ItemCount int
+
+	// TotalRank is the sum of the ranks of the items indexed under the token
+	TotalRank int
+}
+
+// Suggest returns up to n tokens from the indexed vocabulary that are within a small edit distance
+// of the query, e.g. for offering a "did you mean" correction when PrefixSearch returns nothing.
+// The query is normalized before matching.
+// Suggestions are sorted by ascending edit distance, then by the number of items and the total
+// rank they cover (descending). If n <= 0, all suggestions are returned.
+//
+// If the index was created with WithSymSpell, candidates are looked up in the deletion dictionary,
+// otherwise the whole vocabulary is scanned.
+func (idx *Index[T]) Suggest(query string, n int) []Suggestion {
+	query = idx.tokenizer.NormalizeString(query)
+	if query == "" {
+		return nil
+	}
+
+	var suggestions []Suggestion
+	if idx.deletes != nil {
+		suggestions = idx.suggestFromDeletes(query)
+	} else {
+		suggestions = idx.suggestFromVocabulary(query, defaultSuggestMaxDistance)
+	}
+
+	slices.SortFunc(suggestions, func(a, b Suggestion) int {
+		return cmp.Or(
+			cmp.Compare(a.Distance, b.Distance),
+			cmp.Compare(b.ItemCount, a.ItemCount),
+			cmp.Compare(b.TotalRank, a.TotalRank),
+			cmp.Compare(a.Text, b.Text),
+		)
+	})
+
+	if n > 0 && len(suggestions) > n {
+		suggestions = suggestions[:n]
+	}
+	return suggestions
+}
+
+// suggestFromVocabulary scans every token in the index for suggestions.
+func (idx *Index[T]) suggestFromVocabulary(query string, maxDistance int) []Suggestion {
+	queryRunes := []rune(query)
+	var suggestions []Suggestion
+	idx.index.Root().Walk(func(token []byte, postings []posting[T]) bool {
+		if isNamespacedToken(string(token)) {
+			return false
+		}
+		if suggestion, ok := newSuggestion(queryRunes, string(token), postings, maxDistance); ok {
+			suggestions = append(suggestions, suggestion)
+		}
+		return false
+	})
+	return suggestions
+}
+
+// suggestFromDeletes looks up candidate tokens through the deletion dictionary and verifies their distance.
+func (idx *Index[T]) suggestFromDeletes(query string) []Suggestion {
+	queryRunes := []rune(query)
+	root := idx.index.Root()
+	deletesRoot := idx.deletes.Root()
+
+	checked := make(map[string]struct{})
+	var suggestions []Suggestion
+	for variant := range computeDeletes(queryRunes, idx.symSpell) {
+		candidates, found := deletesRoot.Get([]byte(variant))
+		if !found {
+			continue
+		}
+		for _, token := range candidates {
+			if _, exists := checked[token]; exists {
+				continue
+			}
+			checked[token] = struct{}{}
+
+			postings, found := root.Get([]byte(token))
+			if !found {
+				continue
+			}
+			if suggestion, ok := newSuggestion(queryRunes, token, postings, idx.symSpell.MaxEditDistance); ok {
+				suggestions = append(suggestions, suggestion)
+			}
+		}
+	}
+	return suggestions
+}
+
+// newSuggestion builds a suggestion for a token if it is within maxDistance of the query.
+func newSuggestion[T IndexableItem](queryRunes []rune, token string, postings []posting[T], maxDistance int) (Suggestion, bool) {
+	distance := utils.LevenshteinDistance(queryRunes, []rune(token), maxDistance)
+	if distance > maxDistance {
+		return Suggestion{}, false
+	}
+
+	suggestion := Suggestion{
+		Text:      token,
+		Distance:  distance,
+		ItemCount: len(postings),
+	}
+	for _, p := range postings {
+		suggestion.TotalRank += p.item.GetRank()
+	}
+	return suggestion, true
+}
+
+// addToDeletionDictionary adds all deleted variants of the token to the deletion dictionary.
+func addToDeletionDictionary(tx *iradix.Txn[[]string], token string, config *SymSpellConfig) {
+	for variant := range computeDeletes([]rune(token), config) {
+		key := []byte(variant)
+		tokens, _ := tx.Get(key)
+		// Copy to avoid mutating slices shared with previous versions of the tree
+		tx.Insert(key, append(slices.Clip(tokens), token))
+	}
+}
+
+// computeDeletes computes the set of strings obtainable by deleting up to MaxEditDistance characters
+// from the first PrefixLength characters of the value. The truncated value itself is included, and so is
+// the empty string for values of up to MaxEditDistance characters, so that short queries and short
+// tokens share a delete.
+func computeDeletes(value []rune, config *SymSpellConfig) map[string]struct{} {
+	if len(value) > config.PrefixLength {
+		value = value[:config.PrefixLength]
+	}
+
+	deletes := map[string]struct{}{string(value): {}}
+	frontier := [][]rune{value}
+	for range config.MaxEditDistance {
+		var next [][]rune
+		for _, variant := range frontier {
+			if len(variant) == 0 {
+				continue
+			}
+			for i := range variant {
+				deleted := slices.Concat(variant[:i], variant[i+1:])
+				key := string(deleted)
+				if _, exists := deletes[key]; exists {
+					continue
+				}
+				deletes[key] = struct{}{}
+				next = append(next, deleted)
+			}
+		}
+		frontier = next
+	}
+	return deletes
+}
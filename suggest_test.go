@@ -0,0 +1,94 @@
+package lodestar
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestSuggest(t *testing.T) {
+	items := []*ExampleItem{
+		{Text: "apple", Rank: 10},
+		{Text: "apple pie", Rank: 4, Aliases: []string{"apple"}},
+		{Text: "ample", Rank: 3},
+		{Text: "maple", Rank: 7},
+		{Text: "banana", Rank: 12},
+	}
+
+	for _, tc := range []struct {
+		name  string
+		index *Index[*ExampleItem]
+	}{
+		{name: "vocabulary scan", index: setupIndexWithItems(items)},
+		{name: "symspell", index: func() *Index[*ExampleItem] {
+			index, _ := New[*ExampleItem](WithSymSpell(2, 7)).IndexItems(items)
+			return index
+		}()},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			suggestions := tc.index.Suggest("Aple", 0)
+			got := make([]string, 0, len(suggestions))
+			for _, s := range suggestions {
+				got = append(got, s.Text)
+			}
+
+			// "apple" covers two items, so it ranks above the other single edits, "pie" is two edits away
+			want := []string{"apple", "maple", "ample", "pie"}
+			if len(got) != len(want) {
+				t.Fatalf("Suggest() got %v, want %v", got, want)
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("Suggest() got %v, want %v", got, want)
+				}
+			}
+
+			if suggestions[0].Distance != 1 || suggestions[0].ItemCount != 2 || suggestions[0].TotalRank != 14 {
+				t.Errorf("Unexpected suggestion for 'apple': %+v", suggestions[0])
+			}
+
+			if limited := tc.index.Suggest("aple", 1); len(limited) != 1 {
+				t.Errorf("Expected 1 suggestion with limit, got %d", len(limited))
+			}
+			if none := tc.index.Suggest("zzzzzz", 0); len(none) != 0 {
+				t.Errorf("Expected no suggestions, got %v", none)
+			}
+		})
+	}
+}
+
+func TestSuggest_SymSpellMatchesScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	alphabet := []rune("abcdé")
+	randomWord := func() string {
+		word := make([]rune, 1+rng.Intn(4))
+		for i := range word {
+			word[i] = alphabet[rng.Intn(len(alphabet))]
+		}
+		return string(word)
+	}
+	texts := func(suggestions []Suggestion) []string {
+		texts := make([]string, 0, len(suggestions))
+		for _, s := range suggestions {
+			texts = append(texts, s.Text)
+		}
+		return texts
+	}
+
+	for range 50 {
+		var items []*ExampleItem
+		for i := range 1 + rng.Intn(8) {
+			items = append(items, &ExampleItem{Text: randomWord(), Rank: i})
+		}
+		scan := setupIndexWithItems(items)
+		symSpell, _ := New[*ExampleItem](WithSymSpell(defaultSuggestMaxDistance, 7)).IndexItems(items)
+
+		for range 10 {
+			query := randomWord()
+			want, got := texts(scan.Suggest(query, 0)), texts(symSpell.Suggest(query, 0))
+			if !slices.Equal(got, want) {
+				t.Fatalf("Suggest(%q) over %v: symspell got %v, scan got %v", query, resultTexts(items), got, want)
+			}
+		}
+	}
+}
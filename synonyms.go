@@ -0,0 +1,94 @@
+package lodestar
+
+import (
+	"slices"
+	"strings"
+)
+
+// maxSynonymAlternatives bounds the number of alternatives a query is expanded into.
+const maxSynonymAlternatives = 16
+
+// Synonyms is a synonym graph used to rewrite queries into alternative queries, e.g. "tv" into
+// "television" or "nyc" into "new york city". Terms may consist of several words.
+// Terms are matched against whole words of the normalized query, and the alternatives are
+// normalized by the tokenizer of the index before searching.
+//
+// Synonyms must not be modified once the index using it is searched.
+type Synonyms struct {
+	// Alternatives of each term, keyed by its lowercased words joined by single spaces
+	alternatives map[string][]string
+
+	// Maximum number of words in a term
+	maxWords int
+}
+
+// NewSynonyms creates an empty synonym graph.
+func NewSynonyms() *Synonyms {
+	return &Synonyms{alternatives: make(map[string][]string)}
+}
+
+// Add adds a group of equivalent terms, each of which is rewritten into all others.
+func (s *Synonyms) Add(terms ...string) {
+	for i, term := range terms {
+		for j, alternative := range terms {
+			if i != j {
+				s.AddOneWay(term, alternative)
+			}
+		}
+	}
+}
+
+// AddOneWay adds alternatives that a term is rewritten into, but not the other way around,
+// e.g. "laptop" into "notebook" without "notebook" being rewritten into "laptop".
+func (s *Synonyms) AddOneWay(term string, alternatives ...string) {
+	words := strings.Fields(strings.ToLower(term))
+	if len(words) == 0 {
+		return
+	}
+	key := strings.Join(words, " ")
+	for _, alternative := range alternatives {
+		alternative = strings.Join(strings.Fields(strings.ToLower(alternative)), " ")
+		if alternative == "" || alternative == key || slices.Contains(s.alternatives[key], alternative) {
+			continue
+		}
+		s.alternatives[key] = append(s.alternatives[key], alternative)
+	}
+	s.maxWords = max(s.maxWords, len(words))
+}
+
+// expand rewrites the query into alternatives by replacing terms with their synonyms.
+// The literal query is not included, and at most maxSynonymAlternatives alternatives are returned.
+func (s *Synonyms) expand(query string) []string {
+	words := strings.Fields(query)
+	if len(words) == 0 || len(s.alternatives) == 0 {
+		return nil
+	}
+
+	// expansions[i] holds the expansions of the words from i to the end, with the literal words first
+	expansions := make([][]string, len(words)+1)
+	expansions[len(words)] = []string{""}
+	for i := len(words) - 1; i >= 0; i-- {
+		var current []string
+		appendExpansions := func(head string, tails []string) {
+			for _, tail := range tails {
+				if len(current) > maxSynonymAlternatives {
+					return
+				}
+				if tail == "" {
+					current = append(current, head)
+				} else {
+					current = append(current, head+" "+tail)
+				}
+			}
+		}
+
+		appendExpansions(words[i], expansions[i+1])
+		for n := 1; n <= s.maxWords && i+n <= len(words); n++ {
+			for _, alternative := range s.alternatives[strings.Join(words[i:i+n], " ")] {
+				appendExpansions(alternative, expansions[i+n])
+			}
+		}
+		expansions[i] = current
+	}
+	return expansions[0][1:]
+}
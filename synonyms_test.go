@@ -0,0 +1,96 @@
+package lodestar
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSynonymsExpand(t *testing.T) {
+	synonyms := NewSynonyms()
+	synonyms.Add("NYC", "new york city", "big apple")
+	synonyms.AddOneWay("tv", "television")
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"tv", []string{"television"}},
+		{"television", nil},
+		{"nyc tv", []string{"nyc television", "new york city tv", "new york city television", "big apple tv", "big apple television"}},
+		{"new york city hotels", []string{"nyc hotels", "big apple hotels"}},
+		{"new york", nil},
+	}
+	for _, tt := range tests {
+		if got := synonyms.expand(tt.query); !slices.Equal(got, tt.want) {
+			t.Errorf("expand(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestPrefixSearchSynonyms(t *testing.T) {
+	items := []*ExampleItem{
+		{Text: "television stand", Rank: 10},
+		{Text: "tv guide", Rank: 5},
+		{Text: "new york city guide", Rank: 8},
+		{Text: "nyc subway map", Rank: 6},
+	}
+	synonyms := NewSynonyms()
+	synonyms.AddOneWay("tv", "television")
+	synonyms.Add("nyc", "new york city")
+	index, _ := New[*ExampleItem](WithSynonyms(synonyms, 0.6)).IndexItems(items)
+
+	// The synonym match scores 10 * 0.4 = 4, below the literal match
+	results, _ := index.PrefixSearch("tv", 0, nil)
+	if got, want := resultTexts(results), []string{"tv guide", "television stand"}; !slices.Equal(got, want) {
+		t.Errorf("Search for tv got %v, want %v", got, want)
+	}
+
+	results, _ = index.PrefixSearch("NYC", 0, nil)
+	if got, want := resultTexts(results), []string{"nyc subway map", "new york city guide"}; !slices.Equal(got, want) {
+		t.Errorf("Search for nyc got %v, want %v", got, want)
+	}
+
+	// Synonyms are not rewritten the other way around for one way synonyms
+	results, _ = index.PrefixSearch("television", 0, nil)
+	if got, want := resultTexts(results), []string{"television stand"}; !slices.Equal(got, want) {
+		t.Errorf("Search for television got %v, want %v", got, want)
+	}
+
+	explanation := index.Explain("tv", 0, nil)
+	if r := explanation.Results[1]; r.Alternative != "television" || r.Score != 4 {
+		t.Errorf("Unexpected explanation: %+v", r)
+	}
+
+	session := index.NewSession(0)
+	results, _ = session.PrefixSearch("tv", 0, nil)
+	if got, want := resultTexts(results), []string{"tv guide", "television stand"}; !slices.Equal(got, want) {
+		t.Errorf("Session search for tv got %v, want %v", got, want)
+	}
+}
+
+func TestPrefixSearchSynonymsFullPenalty(t *testing.T) {
+	items := []*ExampleItem{
+		{Text: "television", Rank: 1},
+		{Text: "tv"},
+		{Text: "television", Rank: 10},
+	}
+	synonyms := NewSynonyms()
+	synonyms.Add("tv", "television")
+	index, _ := New[*ExampleItem](WithSynonyms(synonyms, 1)).IndexItems(items)
+
+	// A penalty of 1 scales the synonym matches down without collapsing them into a tie,
+	// so the early exit of limited searches keeps the order of the unlimited search
+	all, _ := index.PrefixSearch("tv", 0, nil)
+	if len(all) != len(items) {
+		t.Fatalf("Expected %d results, got %v", len(items), resultTexts(all))
+	}
+	if all[0] != items[2] || all[1] != items[0] {
+		t.Errorf("Expected the synonym matches in rank order, got %+v", all)
+	}
+	for limit := 1; limit < len(all); limit++ {
+		results, _ := index.PrefixSearch("tv", limit, nil)
+		if !slices.Equal(results, all[:limit]) {
+			t.Errorf("Search with limit %d got %v, want %v", limit, resultTexts(results), resultTexts(all[:limit]))
+		}
+	}
+}
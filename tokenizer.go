@@ -1,98 +1,214 @@
 package lodestar
 
 import (
-	"log/slog"
+	"slices"
 	"strings"
+	"sync"
+)
+
+// valueItem wraps a single value as an IndexableItem, so that tokenizers can be applied to one value at a time.
+type valueItem struct {
+	value string
+}
+
+func (v *valueItem) GetValuesForIndexing() []string { return []string{v.value} }
+func (v *valueItem) GetRank() int                   { return 0 }
+func (v *valueItem) GetID() any                     { return v.value }
+
+// weightedValueItem presents one weighted value of an item to a tokenizer, keeping the rank and ID of
+// the item.
+type weightedValueItem struct {
+	IndexableItem
+	value string
+}
+
+func (v *weightedValueItem) GetValuesForIndexing() []string { return []string{v.value} }
+
+// tokenizerWrapper is embedded by tokenizers that wrap a Tokenizer, forwarding the methods they do not
+// override to the wrapped tokenizer, so that e.g. its query forms are still searched.
+type tokenizerWrapper struct {
+	Tokenizer Tokenizer
+}
+
+// NormalizeString normalizes the value with the wrapped tokenizer.
+func (t *tokenizerWrapper) NormalizeString(value string) string {
+	return t.Tokenizer.NormalizeString(value)
+}
+
+// TokenizeQuery returns the normalized forms of the query from the wrapped tokenizer.
+func (t *tokenizerWrapper) TokenizeQuery(query string) []string {
+	return tokenizeQuery(t.Tokenizer, query)
+}
+
+// queryForms returns the forms of the wrapped tokenizer.
+func (t *tokenizerWrapper) queryForms(normalized string) []queryForm {
+	return tokenizerQueryForms(t.Tokenizer, normalized)
+}
+
+// phoneticForms returns the phonetic tokens of the wrapped tokenizer.
+func (t *tokenizerWrapper) phoneticForms(normalized string) []string {
+	return tokenizerPhoneticForms(t.Tokenizer, normalized)
+}
+
+// Analyze returns the tokens of the wrapped tokenizer.
+func (t *tokenizerWrapper) Analyze(value string) []TokenInfo {
+	return analyzeValue(t.Tokenizer, value)
+}
+
+// tokenVariation returns the rule of the wrapped tokenizer that produced the token, if it can tell.
+func (t *tokenizerWrapper) tokenVariation(value string, token string) TokenVariation {
+	if explainer, ok := t.Tokenizer.(tokenVariationExplainer); ok {
+		return explainer.tokenVariation(value, token)
+	}
+	return TokenVariationUnknown
+}
+
+// Defaults of the DefaultTokenizerConfig thresholds.
+const (
+	defaultUnderscoreMinLength = 4
+	defaultDashlessMinLength   = 4
+	defaultVariationMinLength  = 4
 )
 
 // DefaultTokenizer implements the Tokenizer interface with basic tokenization logic.
 // The string is normalized by lowercasing and trimming whitespace.
 // Underscores are replaced with spaces for terms longer than 3 characters.
 // It tokenizes strings by splitting on whitespace and computing prefix combinations with and without hyphens.
-type DefaultTokenizer struct{}
+// Queries are searched with hyphens replaced by spaces and brackets removed as well, see TokenizeQuery,
+// so that e.g. "just-works" finds "it just works" and Get("hello-world") finds "hello world".
+// It is the preset of the Analyzer created by NewDefaultAnalyzer, and its rules can be changed with Config.
+// Stopwords and Config must not be changed after the tokenizer is first used.
+type DefaultTokenizer struct {
+	// Stopwords do not start prefix combinations, unless all words of a value are stopwords, but are
+	// kept inside them. Leading stopwords are also removed from normalized strings, so that queries
+	// ignore them. If nil, all words start prefix combinations, e.g. BuiltinStopwords("en") can be used.
+	Stopwords Stopwords
+
+	// Config changes the thresholds and rules of the tokenizer. The zero value keeps the defaults.
+	Config DefaultTokenizerConfig
+
+	// preset is the analyzer the tokenizer is expressed as, built on first use from Stopwords and Config,
+	// which must not be changed afterwards.
+	preset     *Analyzer
+	presetOnce sync.Once
+}
 
-func (t *DefaultTokenizer) Tokenize(item IndexableItem) []string {
-	var allTokens []string
-	for _, value := range item.GetValuesForIndexing() {
-		tokens := t.tokenizeString(value)
-		if tokens != nil {
-			allTokens = mergeUniqueTokens(allTokens, tokens)
-		} else {
-			slog.Warn("Tokenization for item value returned an empty set", "value", value, "item", item)
-		}
-	}
-	return allTokens
+// DefaultTokenizerConfig holds the thresholds and rules of a DefaultTokenizer.
+// Zero values keep the defaults, so that only the fields to change need to be set.
+type DefaultTokenizerConfig struct {
+	// UnderscoreMinLength is the length in bytes from which underscores in normalized values are
+	// replaced with spaces. Defaults to 4.
+	UnderscoreMinLength int
+
+	// DashlessMinLength is the length in bytes from which the word suffixes of values with hyphens
+	// are also indexed with hyphens replaced by spaces. Defaults to 4.
+	DashlessMinLength int
+
+	// VariationMinLength is the length in bytes from which tokens are also indexed with their brackets
+	// and Punctuation stripped. Defaults to 4.
+	VariationMinLength int
+
+	// Brackets are the characters stripped from tokens by the bracket variation. Defaults to "()[]{}".
+	Brackets string
+
+	// Separators are characters that split words like whitespace, e.g. "/|". None by default.
+	Separators string
+
+	// Punctuation are characters stripped from tokens together with the Brackets, e.g. `"'./`.
+	// None by default.
+	Punctuation string
+
+	// MaxSuffixWords truncates word suffixes to their first MaxSuffixWords words, bounding the size of
+	// the tokens of long values. Queries with more words no longer match. Unlimited by default.
+	MaxSuffixWords int
+
+	// DisableUnderscores keeps underscores instead of replacing them with spaces.
+	DisableUnderscores bool
+
+	// DisableDashless stops indexing the word suffixes of values with hyphens replaced by spaces.
+	DisableDashless bool
+
+	// DisableBracketStripping stops indexing tokens with their brackets and Punctuation stripped.
+	DisableBracketStripping bool
 }
 
-func (t *DefaultTokenizer) tokenizeString(value string) []string {
-	// Normalize the term
-	normalizedValue := t.NormalizeString(value)
-	terms := splitOnWhitespace(normalizedValue)
-	tokens := computePrefixCombinations(terms)
-	if len(tokens) == 0 {
-		return nil
+// withDefaults returns the config with the defaults of unset fields.
+func (c DefaultTokenizerConfig) withDefaults() DefaultTokenizerConfig {
+	if c.UnderscoreMinLength <= 0 {
+		c.UnderscoreMinLength = defaultUnderscoreMinLength
 	}
-
-	// If the value has dashes, also compute this variation
-	if strings.Contains(value, "-") && len(value) > 3 {
-		dashlessString := strings.ReplaceAll(normalizedValue, "-", " ")
-		dashlessTokens := computePrefixCombinations(splitOnWhitespace(dashlessString))
-		tokens = mergeUniqueTokens(tokens, dashlessTokens)
+	if c.DashlessMinLength <= 0 {
+		c.DashlessMinLength = defaultDashlessMinLength
+	}
+	if c.VariationMinLength <= 0 {
+		c.VariationMinLength = defaultVariationMinLength
 	}
+	if c.Brackets == "" {
+		c.Brackets = bracketChars
+	}
+	return c
+}
+
+func (t *DefaultTokenizer) Tokenize(item IndexableItem) []string {
+	return t.analyzer().Tokenize(item)
+}
 
-	return computeTokenVariations(tokens)
+// Analyze returns the tokens of the value with the rule that produced them and their position.
+func (t *DefaultTokenizer) Analyze(value string) []TokenInfo {
+	return t.analyzer().Analyze(value)
+}
+
+// tokenVariation returns the rule that produced the token from the value.
+func (t *DefaultTokenizer) tokenVariation(value string, token string) TokenVariation {
+	return t.analyzer().tokenVariation(value, token)
 }
 
 // NormalizeString normalizes a term by converting it to lowercase and removing leading/trailing whitespace.
 // If the term is longer than 3 characters, it also replaces underscores with spaces.
+// Leading stopwords are removed as long as another word follows.
 func (t *DefaultTokenizer) NormalizeString(value string) string {
-	normalized := strings.ToLower(value)
-	normalized = strings.TrimSpace(normalized)
-	if len(normalized) > 3 {
-		normalized = strings.ReplaceAll(normalized, "_", " ")
+	return t.analyzer().NormalizeString(value)
+}
+
+// TokenizeQuery returns the normalized query, followed by its variants with hyphens replaced by spaces
+// and brackets removed, if they differ. PrefixSearch and Get search every variant, so their results
+// include values without the hyphens and brackets of the query.
+func (t *DefaultTokenizer) TokenizeQuery(query string) []string {
+	return t.analyzer().TokenizeQuery(query)
+}
+
+// analyzer returns the preset the tokenizer is expressed as, building it once.
+func (t *DefaultTokenizer) analyzer() *Analyzer {
+	t.presetOnce.Do(func() {
+		t.preset = t.buildAnalyzer()
+	})
+	return t.preset
+}
+
+// buildAnalyzer builds the preset of the stopwords and the config.
+func (t *DefaultTokenizer) buildAnalyzer() *Analyzer {
+	config := t.Config.withDefaults()
+
+	analyzer := NewAnalyzer().WithCharFilters(LowercaseCharFilter(), TrimSpaceCharFilter())
+	if !config.DisableUnderscores {
+		analyzer.WithCharFilters(UnderscoreCharFilter(config.UnderscoreMinLength))
 	}
-	return normalized
-}
-
-// computeTokenVariations computes variations of tokens with special characters that may appear in
-// words removed, like bracket.
-// This allows us to support searching for values with and without brackets, e.g.:
-// "(hello world)" when searching for "hello world" and "(hello world)"
-// The order of tokens is not preserved. Any added variations will be unique.
-func computeTokenVariations(tokens []string) []string {
-	// Use a set to store unique tokens and their variations.
-	variationSet := make(map[string]struct{}, len(tokens)*2) // Pre-allocate for variations
-	// Replacer for removing all bracket types efficiently.
-	bracketReplacer := strings.NewReplacer("(", "", ")", "", "[", "", "]", "", "{", "", "}", "")
-
-	for _, token := range tokens {
-		// Always add the original token.
-		variationSet[token] = struct{}{}
-
-		// Ignore short tokens for further variations.
-		if len(token) < 4 {
-			continue
-		}
-		// Generate variations only if special characters are present.
-		if strings.ContainsAny(token, "()[]{}") {
-			// Variation with brackets removed.
-			noBrackets := bracketReplacer.Replace(token)
-			if noBrackets != token {
-				variationSet[noBrackets] = struct{}{}
-			}
-		}
+	if config.Separators != "" {
+		analyzer.WithCharFilters(SeparatorCharFilter(config.Separators))
 	}
 
-	if len(variationSet) == len(tokens) {
-		return tokens
+	analyzer.WithTokenFilters(SuffixFilter())
+	if !config.DisableDashless {
+		analyzer.WithTokenFilters(HyphenFilter(config.DashlessMinLength))
 	}
-
-	// Convert the set back to a slice.
-	result := make([]string, 0, len(variationSet))
-	for v := range variationSet {
-		result = append(result, v)
+	if config.MaxSuffixWords > 0 {
+		analyzer.WithTokenFilters(MaxWordsFilter(config.MaxSuffixWords))
 	}
-	return result
+	analyzer.WithTokenFilters(StopwordsFilter(t.Stopwords))
+	if !config.DisableBracketStripping {
+		analyzer.WithTokenFilters(StripFilter(config.VariationMinLength, config.Brackets+config.Punctuation))
+	}
+	return analyzer
 }
 
 // computePrefixCombinations computes prefix combinations of tokens.
@@ -128,12 +244,11 @@ func mergeUniqueTokens(tokens ...[]string) []string {
 		}
 	}
 
-	// Convert the map keys back to a slice
+	// Convert the map keys back to a slice, sorted for deterministic output
 	result := make([]string, 0, len(tokenSet))
 	for token := range tokenSet {
 		result = append(result, token)
 	}
-
-	// TODO: sort for deterministic output?
+	slices.Sort(result)
 	return result
 }
@@ -1,6 +1,7 @@
 package lodestar
 
 import (
+	"slices"
 	"testing"
 )
 
@@ -91,3 +92,143 @@ func Test_defaultTokenizer_Tokenize(t *testing.T) {
 		})
 	}
 }
+
+func Test_defaultTokenizer_tokenVariation(t *testing.T) {
+	tr := &DefaultTokenizer{}
+	tests := []struct {
+		value string
+		token string
+		want  TokenVariation
+	}{
+		{value: "it (just) works", token: "(just) works", want: TokenVariationWordSuffix},
+		{value: "it (just) works", token: "just works", want: TokenVariationBracketStripped},
+		{value: "it-just-works", token: "just works", want: TokenVariationDashless},
+		{value: "it-just-works", token: "other", want: TokenVariationUnknown},
+	}
+	for _, tt := range tests {
+		if got := tr.tokenVariation(tt.value, tt.token); got != tt.want {
+			t.Errorf("tokenVariation(%q, %q) = %v, want %v", tt.value, tt.token, got, tt.want)
+		}
+	}
+}
+
+func Test_defaultTokenizer_Stopwords(t *testing.T) {
+	tr := &DefaultTokenizer{Stopwords: BuiltinStopwords("en")}
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{value: "the quick brown fox", want: []string{"brown fox", "fox", "quick brown fox"}},
+		{value: "jack of all trades", want: []string{"all trades", "jack of all trades", "trades"}},
+		{value: "the-end of days", want: []string{"days", "end of days", "the-end of days"}},
+		{value: "to be or not to be", want: []string{"be", "be or not to be", "not to be", "or not to be", "to be", "to be or not to be"}},
+	}
+	for _, tt := range tests {
+		got := tr.Tokenize(&valueItem{value: tt.value})
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	queries := map[string]string{
+		"The Quick":  "quick",
+		"the of qu":  "qu",
+		"the":        "the",
+		"theater":    "theater",
+		"jack of a":  "jack of a",
+		"  a  tale ": "tale",
+	}
+	for query, want := range queries {
+		if got := tr.NormalizeString(query); got != want {
+			t.Errorf("NormalizeString(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
+
+func Test_defaultTokenizer_Config(t *testing.T) {
+	tests := []struct {
+		config DefaultTokenizerConfig
+		value  string
+		want   []string
+	}{
+		{
+			config: DefaultTokenizerConfig{Separators: "/|"},
+			value:  "docs/api|v2",
+			want:   []string{"api v2", "docs api v2", "v2"},
+		},
+		{
+			config: DefaultTokenizerConfig{Punctuation: `".`},
+			value:  `"Mr. Smith" (x)`,
+			want:   []string{`"mr. smith" (x)`, "(x)", "mr smith x", "smith x", `smith" (x)`},
+		},
+		{
+			config: DefaultTokenizerConfig{MaxSuffixWords: 2},
+			value:  "one two three four",
+			want:   []string{"four", "one two", "three four", "two three"},
+		},
+		{
+			config: DefaultTokenizerConfig{UnderscoreMinLength: 3},
+			value:  "a_b",
+			want:   []string{"a b", "b"},
+		},
+		{
+			config: DefaultTokenizerConfig{DisableUnderscores: true},
+			value:  "snake_case",
+			want:   []string{"snake_case"},
+		},
+		{
+			config: DefaultTokenizerConfig{DashlessMinLength: 6},
+			value:  "x-ray",
+			want:   []string{"x-ray"},
+		},
+		{
+			config: DefaultTokenizerConfig{DisableDashless: true},
+			value:  "it-just-works",
+			want:   []string{"it-just-works"},
+		},
+		{
+			config: DefaultTokenizerConfig{VariationMinLength: 8},
+			value:  "beta (v2)",
+			want:   []string{"(v2)", "beta (v2)", "beta v2"},
+		},
+		{
+			config: DefaultTokenizerConfig{DisableBracketStripping: true},
+			value:  "(hello world)",
+			want:   []string{"(hello world)", "world)"},
+		},
+	}
+	for _, tt := range tests {
+		tr := &DefaultTokenizer{Config: tt.config}
+		if got := tr.Tokenize(&valueItem{value: tt.value}); !slices.Equal(got, tt.want) {
+			t.Errorf("Tokenize(%q) with %+v = %q, want %q", tt.value, tt.config, got, tt.want)
+		}
+	}
+
+	// The zero value and the explicit defaults keep the output of the DefaultTokenizer
+	explicit := &DefaultTokenizer{Config: DefaultTokenizerConfig{
+		UnderscoreMinLength: 4,
+		DashlessMinLength:   4,
+		VariationMinLength:  4,
+		Brackets:            "()[]{}",
+	}}
+	for _, value := range []string{"Hello World", "it-just-works", "a_b", "snake_case_value", "(hello world) [beta]", "x-ray"} {
+		item := &valueItem{value: value}
+		if got, want := explicit.Tokenize(item), (&DefaultTokenizer{}).Tokenize(item); !slices.Equal(got, want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", value, got, want)
+		}
+	}
+
+	// Separators also split queries
+	tr := &DefaultTokenizer{Config: DefaultTokenizerConfig{Separators: "/"}}
+	if got := tr.NormalizeString("Docs/API"); got != "docs api" {
+		t.Errorf("NormalizeString() = %q, want %q", got, "docs api")
+	}
+}
+
+func BenchmarkDefaultTokenizer_TokenizeQuery(b *testing.B) {
+	tr := &DefaultTokenizer{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tr.TokenizeQuery("Big-Bang (Theory)")
+	}
+}
@@ -0,0 +1,82 @@
+package lodestar
+
+import (
+	"bytes"
+	"iter"
+)
+
+// Tokens returns an iterator over every token in the index in lexicographic order,
+// yielding each token with the length of its posting list.
+// Internal tokens, such as the n-grams of an NGramTokenizer and phonetic codes, are skipped.
+func (idx *Index[T]) Tokens() iter.Seq2[string, int] {
+	return idx.TokensWithPrefix("")
+}
+
+// TokensWithPrefix returns an iterator over the tokens starting with the prefix in lexicographic order,
+// yielding each token with the length of its posting list.
+// The prefix is normalized before searching.
+func (idx *Index[T]) TokensWithPrefix(prefix string) iter.Seq2[string, int] {
+	prefix = idx.tokenizer.NormalizeString(prefix)
+	return func(yield func(string, int) bool) {
+		tokenIter := idx.index.Root().Iterator()
+		tokenIter.SeekPrefix([]byte(prefix))
+		for token, postings, ok := tokenIter.Next(); ok; token, postings, ok = tokenIter.Next() {
+			if isNamespacedToken(string(token)) {
+				continue
+			}
+			if !yield(string(token), len(postings)) {
+				return
+			}
+		}
+	}
+}
+
+// TokenRange returns an iterator over the tokens in the lexicographic range [from, to),
+// yielding each token with the length of its posting list. Internal tokens are skipped, see Tokens.
+// The bounds are normalized like the prefix of TokensWithPrefix. An empty upper bound means no upper bound.
+func (idx *Index[T]) TokenRange(from, to string) iter.Seq2[string, int] {
+	from = idx.tokenizer.NormalizeString(from)
+	if to != "" {
+		to = idx.tokenizer.NormalizeString(to)
+	}
+	return func(yield func(string, int) bool) {
+		tokenIter := idx.index.Root().Iterator()
+		tokenIter.SeekLowerBound([]byte(from))
+		for token, postings, ok := tokenIter.Next(); ok; token, postings, ok = tokenIter.Next() {
+			if to != "" && bytes.Compare(token, []byte(to)) >= 0 {
+				return
+			}
+			if isNamespacedToken(string(token)) {
+				continue
+			}
+			if !yield(string(token), len(postings)) {
+				return
+			}
+		}
+	}
+}
+
+// TokensReverse returns an iterator over every token in the index in reverse lexicographic order,
+// yielding each token with the length of its posting list. Internal tokens are skipped, see Tokens.
+func (idx *Index[T]) TokensReverse() iter.Seq2[string, int] {
+	return idx.TokensWithPrefixReverse("")
+}
+
+// TokensWithPrefixReverse returns an iterator over the tokens starting with the prefix in reverse
+// lexicographic order, yielding each token with the length of its posting list.
+// The prefix is normalized before searching.
+func (idx *Index[T]) TokensWithPrefixReverse(prefix string) iter.Seq2[string, int] {
+	prefix = idx.tokenizer.NormalizeString(prefix)
+	return func(yield func(string, int) bool) {
+		tokenIter := idx.index.Root().ReverseIterator()
+		tokenIter.SeekPrefix([]byte(prefix))
+		for token, postings, ok := tokenIter.Previous(); ok; token, postings, ok = tokenIter.Previous() {
+			if isNamespacedToken(string(token)) {
+				continue
+			}
+			if !yield(string(token), len(postings)) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package lodestar
+
+import (
+	"iter"
+	"slices"
+	"testing"
+)
+
+func collectTokens(seq iter.Seq2[string, int]) []string {
+	var tokens []string
+	for token := range seq {
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+func TestTokens(t *testing.T) {
+	index := setupIndexWithItems(testItems)
+
+	all := collectTokens(index.Tokens())
+	if len(all) != index.Len() || !slices.IsSorted(all) {
+		t.Errorf("Tokens() should yield all %d tokens in order, got %v", index.Len(), all)
+	}
+
+	reversed := collectTokens(index.TokensReverse())
+	slices.Reverse(reversed)
+	if !slices.Equal(all, reversed) {
+		t.Errorf("TokensReverse() got %v, want reverse of %v", reversed, all)
+	}
+
+	for token, count := range index.TokensWithPrefix("Fruit") {
+		if token != "fruit" || count != 2 {
+			t.Errorf("TokensWithPrefix('Fruit') got %q with %d items", token, count)
+		}
+	}
+
+	got := collectTokens(index.TokensWithPrefixReverse("app"))
+	want := []string{"approach", "apply", "application", "apple", "app"}
+	if !slices.Equal(got, want) {
+		t.Errorf("TokensWithPrefixReverse('app') got %v, want %v", got, want)
+	}
+
+	got = collectTokens(index.TokenRange("apple", "approach"))
+	want = []string{"apple", "application", "apply"}
+	if !slices.Equal(got, want) {
+		t.Errorf("TokenRange('apple', 'approach') got %v, want %v", got, want)
+	}
+
+	// The bounds are normalized like prefixes
+	if got, want := collectTokens(index.TokenRange("APP", "APQ")), collectTokens(index.TokensWithPrefix("APP")); !slices.Equal(got, want) {
+		t.Errorf("TokenRange('APP', 'APQ') got %v, want %v", got, want)
+	}
+
+	got = collectTokens(index.TokenRange("use", ""))
+	want = []string{"use", "way", "yellow"}
+	if !slices.Equal(got, want) {
+		t.Errorf("TokenRange('use', '') got %v, want %v", got, want)
+	}
+}
+
+func TestTokens_SkipNamespaced(t *testing.T) {
+	index, _ := New[*ExampleItem](WithTokenizer(NewNGramTokenizer(nil, 2, 3, NGramEdge))).IndexItems([]*ExampleItem{
+		{Text: "part ab-123", Rank: 1},
+	})
+	want := []string{"123", "ab 123", "ab-123", "part ab 123", "part ab-123"}
+	for name, seq := range map[string]iter.Seq2[string, int]{
+		"Tokens":           index.Tokens(),
+		"TokensWithPrefix": index.TokensWithPrefix(""),
+		"TokenRange":       index.TokenRange("", ""),
+	} {
+		if got := collectTokens(seq); !slices.Equal(got, want) {
+			t.Errorf("%s() got %v, want %v", name, got, want)
+		}
+	}
+	got := collectTokens(index.TokensReverse())
+	slices.Reverse(got)
+	if !slices.Equal(got, want) {
+		t.Errorf("TokensReverse() got %v, want reverse of %v", got, want)
+	}
+}
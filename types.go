@@ -16,19 +16,48 @@ type IndexableItem interface {
 	GetID() any
 }
 
+// WeightedValue is a value to be indexed with a weight, e.g. 1 for the canonical name of an item
+// and 0.5 for an obscure alias.
+type WeightedValue struct {
+	Text   string
+	Weight float64
+}
+
+// WeightedIndexableItem is an optional interface for items whose values should rank differently.
+// If implemented, its weighted values are indexed instead of GetValuesForIndexing, and a match on a
+// value is ranked by the item rank multiplied by the value weight. Negative ranks are divided by the
+// weight instead, so that lower weights always rank lower.
+// If a token is produced by several values, the highest weight is used.
+// The tokenizer is called once per weighted value, with an item that returns the rank and ID of this
+// item but only the weighted value from GetValuesForIndexing, so it cannot be type asserted to the item.
+type WeightedIndexableItem interface {
+	IndexableItem
+
+	// GetWeightedValuesForIndexing returns one or more weighted strings associated with the item.
+	GetWeightedValuesForIndexing() []WeightedValue
+}
+
 // Tokenizer defines the interface for tokenizing items before indexing
 type Tokenizer interface {
 	// Tokenize tokenizes the values of an IndexableItem into a set of unique tokens.
+	// Items implementing WeightedIndexableItem are passed one weighted value at a time, wrapped in an
+	// item with the same rank and ID.
 	Tokenize(item IndexableItem) []string
 
-	// TokenizeString tokenizes a single string value into a set of unique tokens.
-	// TokenizeString(value string) []string
-
 	// NormalizeString normalizes a string for tokenization (e.g. lowercasing, removing punctuation).
 	// This is also applied to all queries before searching
 	NormalizeString(value string) string
 }
 
+// QueryTokenizer is an optional interface for tokenizers that analyze queries into several forms, e.g. to
+// split or expand queries the way values are tokenized. If implemented, it is used instead of
+// NormalizeString by PrefixSearch and Get, which search every form and merge their matches.
+type QueryTokenizer interface {
+	// TokenizeQuery returns the normalized forms of a query, starting with the normalized query itself.
+	// The first form is the normalized query passed to filter functions.
+	TokenizeQuery(query string) []string
+}
+
 // ResultFilterFn is a function to filter results based on custom logic.
 // It takes the normalized original query, matching token, and item as parameters and returns true if the item
 // should be included in aggregation.
@@ -42,4 +71,11 @@ type QueryTimingInfo struct {
 	SeekTime        time.Duration
 	AggregationTime time.Duration
 	TotalTime       time.Duration
+
+	// Narrowed is set if a Session narrowed the candidates of its previous query instead of seeking.
+	// SeekTime then holds the time spent narrowing.
+	Narrowed bool
+
+	// ReusedCandidates is the number of candidate tokens reused from the previous query of a Session.
+	ReusedCandidates int
 }
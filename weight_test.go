@@ -0,0 +1,117 @@
+package lodestar
+
+import (
+	"slices"
+	"testing"
+)
+
+type weightedItem struct {
+	Name    string
+	Rank    int
+	Aliases []string
+}
+
+func (w *weightedItem) GetValuesForIndexing() []string {
+	return append([]string{w.Name}, w.Aliases...)
+}
+func (w *weightedItem) GetRank() int {
+	return w.Rank
+}
+func (w *weightedItem) GetID() any {
+	return w
+}
+func (w *weightedItem) GetWeightedValuesForIndexing() []WeightedValue {
+	values := []WeightedValue{{Text: w.Name, Weight: 1}}
+	for _, alias := range w.Aliases {
+		values = append(values, WeightedValue{Text: alias, Weight: 0.25})
+	}
+	return values
+}
+
+func TestPrefixSearchWeightedValues(t *testing.T) {
+	items := []*weightedItem{
+		{Name: "application", Rank: 10, Aliases: []string{"app"}},
+		{Name: "apple", Rank: 6},
+		{Name: "approach", Rank: 4},
+		{Name: "banana", Rank: 20, Aliases: []string{"appetizer"}},
+	}
+	index, _ := New[*weightedItem]().IndexItems(items)
+
+	// The banana only matches through its alias, so it scores 20 * 0.25 = 5
+	// The application matches its own name as well as its alias, so it keeps the higher score of 10
+	results, _ := index.PrefixSearch("app", 0, nil)
+	want := []string{"application", "apple", "banana", "approach"}
+	if got := resultTexts(results); !slices.Equal(got, want) {
+		t.Errorf("Weighted search got %v, want %v", got, want)
+	}
+
+	for _, limit := range []int{1, 2, 3} {
+		results, _ = index.PrefixSearch("app", limit, nil)
+		if got := resultTexts(results); !slices.Equal(got, want[:limit]) {
+			t.Errorf("Weighted search with limit %d got %v, want %v", limit, got, want[:limit])
+		}
+	}
+
+	results, _ = index.PrefixSearch("app", 0, nil, WithGroupBy(func(*weightedItem) any { return "all" }, 2))
+	if got := resultTexts(results); !slices.Equal(got, want[:2]) {
+		t.Errorf("Weighted grouped search got %v, want %v", got, want[:2])
+	}
+
+	explanation := index.Explain("app", 1, nil)
+	if r := explanation.Results[0]; r.Token != "application" || r.Weight != 1 || r.Score != 10 {
+		t.Errorf("Unexpected explanation: %+v", r)
+	}
+	explanation = index.Explain("appe", 1, nil)
+	if r := explanation.Results[0]; r.Value != "appetizer" || r.Weight != 0.25 || r.Score != 5 {
+		t.Errorf("Unexpected explanation: %+v", r)
+	}
+}
+
+func TestPrefixSearchWeightedNegativeRanks(t *testing.T) {
+	items := []*weightedItem{
+		{Name: "banana", Rank: -10, Aliases: []string{"appetizer"}},
+		{Name: "apple", Rank: -10},
+		{Name: "approach", Rank: -30},
+	}
+	index, _ := New[*weightedItem]().IndexItems(items)
+
+	// The banana only matches through its alias, so it scores -10 / 0.25 = -40, below the apple
+	want := []string{"apple", "approach", "banana"}
+	for _, limit := range []int{1, 2, 3} {
+		results, _ := index.PrefixSearch("app", limit, nil)
+		if got := resultTexts(results); !slices.Equal(got, want[:limit]) {
+			t.Errorf("Search with limit %d got %v, want %v", limit, got, want[:limit])
+		}
+	}
+}
+
+// recordingTokenizer records the IDs and values of the items it tokenizes.
+type recordingTokenizer struct {
+	DefaultTokenizer
+	ids    []any
+	values []string
+}
+
+func (t *recordingTokenizer) Tokenize(item IndexableItem) []string {
+	t.ids = append(t.ids, item.GetID())
+	t.values = append(t.values, item.GetValuesForIndexing()...)
+	return t.DefaultTokenizer.Tokenize(item)
+}
+
+func TestTokenizeWeightedValuesKeepsItem(t *testing.T) {
+	item := &weightedItem{Name: "application", Rank: 10, Aliases: []string{"app"}}
+	tokenizer := &recordingTokenizer{}
+	if _, err := New[*weightedItem](WithTokenizer(tokenizer)).IndexItems([]*weightedItem{item}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each weighted value is tokenized on its own, with the ID of the item
+	if want := []string{"application", "app"}; !slices.Equal(tokenizer.values, want) {
+		t.Errorf("Tokenized values %v, want %v", tokenizer.values, want)
+	}
+	for _, id := range tokenizer.ids {
+		if id != item {
+			t.Errorf("Tokenized item ID %v, want %v", id, item)
+		}
+	}
+}